@@ -16,31 +16,222 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/base64"
 	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/iflow"
+	"github.com/Trifolium-project/landscaper/packages/util"
 	"github.com/spf13/cobra"
 )
 
+var artifactSource *string
+var artifactName *string
+var artifactCreateConfigurations *[]string
+var toDeployCreated *bool
+
 // createCmd represents the create command
 var artifactCreateCmd = &cobra.Command{
 	Use:   "create",
-	Short: "Create artifact(not implemented)",
-	Long: `Create artifact(not implemented)`,
+	Short: "Create artifact from local zip or directory",
+	Long: `Create integration flow from local zip file or unpacked directory.
+If artifact already exists, its content is updated.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("create called(not implemented yet)")
+		artifactCreate()
 	},
 }
 
 func init() {
 	artifactCmd.AddCommand(artifactCreateCmd)
 
+	artifactSource = artifactCreateCmd.Flags().String("from", "", "Path to artifact zip file or directory")
+	artifactName = artifactCreateCmd.Flags().String("name", "", "Artifact name(default is Bundle-Name from manifest)")
+	artifactCreateConfigurations = artifactCreateCmd.Flags().StringSliceP("config", "c", []string{}, "List of configuration key:value pairs")
+	toDeployCreated = artifactCreateCmd.Flags().BoolP("deploy", "d", false, "Indicate whether necessary to deploy artifact")
+
+	artifactCreateCmd.MarkFlagRequired("from")
 	// Here you will define your flags and configuration settings.
 
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
 	// createCmd.PersistentFlags().String("foo", "", "A help for foo")
+}
+
+func artifactCreate() {
+	if globalLandscape == nil {
+		println("Global landscape is not instantiated")
+		return
+	}
+
+	currentEnvironment, err := globalLandscape.GetEnvironment(*environment)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	if *pkg == "" {
+		log.Fatalln("Package is not provided, please use --pkg flag")
+	}
+
+	content, err := readArtifactSource(*artifactSource)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	//Artifact ID and name are taken from manifest, if not passed explicitly
+	manifest, manifestErr := readArtifactManifest(content)
+
+	id := *artifact
+	if id == "" {
+		if manifestErr != nil {
+			log.Fatalln(manifestErr)
+		}
+		if manifest.SymbolicName == "" {
+			log.Fatalln("Bundle-SymbolicName is not found in manifest, please use --artifact flag")
+		}
+		id = currentEnvironment.ArtifactId(manifest.SymbolicName)
+	}
+
+	name := *artifactName
+	if name == "" && manifestErr == nil {
+		name = manifest.Name
+	}
+	if name == "" {
+		name = currentEnvironment.BaseArtifactId(id)
+	}
+	if currentEnvironment != globalLandscape.OriginalEnvironment {
+		name = currentEnvironment.ArtifactName(name)
+	}
+
+	newArtifact := &cpiclient.IntegrationDesigntimeArtifact{
+		Id:              id,
+		Name:            name,
+		PackageId:       *pkg,
+		ArtifactContent: base64.StdEncoding.EncodeToString(content),
+	}
+
+	updated, err := createOrUpdateArtifact(currentEnvironment.System.Client, newArtifact)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	err = applyConfigurationPairs(currentEnvironment.System.Client, id, *artifactCreateConfigurations)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	if *toDeployCreated {
+		err = currentEnvironment.System.Client.DeployIntegrationDesigntimeArtifact(id, "active")
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	result := "created"
+	if updated {
+		result = "updated"
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+	fmt.Fprintf(writer, "===Artifact metadata===\n\n")
+
+	fmt.Fprintf(writer, "%s\t%s\n", "ID:", newArtifact.Id)
+	fmt.Fprintf(writer, "%s\t%s\n", "Name:", newArtifact.Name)
+	fmt.Fprintf(writer, "%s\t%s\n", "Package:", newArtifact.PackageId)
+	fmt.Fprintf(writer, "%s\t%s\n", "Result:", result)
+	fmt.Fprintf(writer, "%s\t%t\n", "Deployed:", *toDeployCreated)
+
+	writer.Flush()
+}
+
+//Upload new artifact, or replace content of existing one. Returns true, if artifact was updated
+func createOrUpdateArtifact(client *cpiclient.CPIClient, newArtifact *cpiclient.IntegrationDesigntimeArtifact) (bool, error) {
+
+	existingArtifact, err := client.ReadIntegrationDesigntimeArtifact(newArtifact.Id, "active")
+	if cpiclient.IsNotFound(err) {
+		return false, client.UploadIntegrationDesigntimeArtifact(newArtifact)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if existingArtifact.PackageId != newArtifact.PackageId {
+		return false, fmt.Errorf("artifact %s already exists in package %s", newArtifact.Id, existingArtifact.PackageId)
+	}
+
+	return true, client.UpdateIntegrationDesigntimeArtifact(newArtifact)
+}
+
+//Apply list of key:value pairs to artifact configuration
+func applyConfigurationPairs(client *cpiclient.CPIClient, artifactId string, pairs []string) error {
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	conf, err := client.ReadIntegrationDesigntimeArtifactConfigurations(artifactId, "active")
+	if err != nil {
+		return err
+	}
+
+	for _, pair := range pairs {
+		confTuple := strings.SplitN(pair, ":", 2)
+		if len(confTuple) != 2 {
+			return fmt.Errorf("error while parsing configuration %s", pair)
+		}
+
+		dataType, err := getConfigurationType(confTuple[0], conf)
+		if err != nil {
+			return err
+		}
+
+		err = client.UpdateIntegrationDesigntimeArtifactConfiguration(artifactId, "active", &cpiclient.Configuration{
+			ParameterKey:   confTuple[0],
+			ParameterValue: confTuple[1],
+			DataType:       dataType,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//Read artifact zip file, or pack directory into zip
+func readArtifactSource(path string) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return util.ZipDirectory(path)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !util.IsZip(content) {
+		return nil, fmt.Errorf("%s is neither zip archive nor directory", path)
+	}
+
+	return content, nil
+}
+
+//Read META-INF/MANIFEST.MF of artifact zip
+func readArtifactManifest(content []byte) (*iflow.Manifest, error) {
+	files, err := util.ReadZipEntries(content)
+	if err != nil {
+		return nil, err
+	}
 
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// createCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	manifest, ok := files["META-INF/MANIFEST.MF"]
+	if !ok {
+		return nil, fmt.Errorf("manifest is not found in artifact")
+	}
+	return iflow.ParseManifest(manifest), nil
 }
@@ -16,31 +16,184 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/base64"
 	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
 
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
+var backupDir *string
+var skipBackup *bool
+var removeFromLandscape *bool
+var undeployTimeout *time.Duration
+
+//Artifact configuration, which is stored next to artifact content
+type ConfigurationFileYAML struct {
+	Parameters []ConfigurationParameterYAML `yaml:"parameters"`
+}
+
+type ConfigurationParameterYAML struct {
+	Key   string `yaml:"key"`
+	Value string `yaml:"value"`
+	Type  string `yaml:"type"`
+}
+
 // deleteCmd represents the delete command
 var artifactDeleteCmd = &cobra.Command{
 	Use:   "delete",
-	Short: "Delete artifact(not implemented)",
-	Long: `Delete artifact(not implemented)`,
+	Short: "Delete artifact",
+	Long: `Undeploy artifact, save backup of its content and configuration, and delete it from design time.
+Optionally references to artifact are removed from landscape file.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("delete called(not implemented yet)")
+		artifactDelete()
 	},
 }
 
 func init() {
 	artifactCmd.AddCommand(artifactDeleteCmd)
 
-	// Here you will define your flags and configuration settings.
+	backupDir = artifactDeleteCmd.Flags().String("backup-dir", "backup", "Directory for artifact backup")
+	skipBackup = artifactDeleteCmd.Flags().Bool("no-backup", false, "Do not save backup of artifact")
+	removeFromLandscape = artifactDeleteCmd.Flags().Bool("remove-from-landscape", false, "Remove references to artifact from landscape file")
+	undeployTimeout = artifactDeleteCmd.Flags().Duration("timeout", 5*time.Minute, "Maximum time to wait for undeploy")
 
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
 	// deleteCmd.PersistentFlags().String("foo", "", "A help for foo")
+}
+
+func artifactDelete() {
+	if globalLandscape == nil {
+		println("Global landscape is not instantiated")
+		return
+	}
+
+	if *artifact == "" {
+		log.Fatalln("Artifact is not provided, please use --artifact flag")
+	}
+
+	currentEnvironment, err := globalLandscape.GetEnvironment(*environment)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	client := currentEnvironment.System.Client
+
+	artfct, err := client.ReadIntegrationDesigntimeArtifact(*artifact, "active")
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	//Undeploy runtime artifact and wait, until it disappears
+	undeployed := false
+	_, err = client.ReadIntegrationRuntimeArtifact(artfct.Id)
+	if err == nil {
+		err = client.UndeployIntegrationRuntimeArtifact(artfct.Id)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		err = client.WaitForIntegrationRuntimeArtifactRemoval(artfct.Id, *undeployTimeout)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		undeployed = true
+	} else if !cpiclient.IsNotFound(err) {
+		log.Fatalln(err)
+	}
+
+	backupFile := "-"
+	if !*skipBackup {
+		backupFile, err = backupArtifact(client, artfct, *backupDir)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	err = client.DeleteIntegrationDesigntimeArtifact(artfct.Id, artfct.Version)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	removedFromLandscape := false
+	if *removeFromLandscape {
+		removedFromLandscape, err = globalLandscape.RemoveArtifactReferences(
+			currentEnvironment.BasePackageId(artfct.PackageId),
+			currentEnvironment.BaseArtifactId(artfct.Id),
+			currentEnvironment.Id,
+		)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+	fmt.Fprintf(writer, "===Deleted artifact===\n\n")
+
+	fmt.Fprintf(writer, "%s\t%s\n", "ID:", artfct.Id)
+	fmt.Fprintf(writer, "%s\t%s\n", "Name:", artfct.Name)
+	fmt.Fprintf(writer, "%s\t%s\n", "Version:", artfct.Version)
+	fmt.Fprintf(writer, "%s\t%s\n", "Package:", artfct.PackageId)
+	fmt.Fprintf(writer, "%s\t%t\n", "Undeployed:", undeployed)
+	fmt.Fprintf(writer, "%s\t%s\n", "Backup:", backupFile)
+	fmt.Fprintf(writer, "%s\t%t\n", "Removed from landscape:", removedFromLandscape)
+
+	writer.Flush()
+}
+
+//Download artifact content and save it together with configuration. Returns path to zip file
+func backupArtifact(client *cpiclient.CPIClient, artfct *cpiclient.IntegrationDesigntimeArtifact, dir string) (string, error) {
+
+	downloadedArtifact, err := client.DownloadIntegrationDesigntimeArtifact(artfct.Id, artfct.Version)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := base64.StdEncoding.DecodeString(downloadedArtifact.ArtifactContent)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	baseName := filepath.Join(dir, fmt.Sprintf("%s_%s_%s", artfct.Id, artfct.Version, time.Now().Format("20060102150405")))
+
+	err = os.WriteFile(baseName+".zip", content, 0644)
+	if err != nil {
+		return "", err
+	}
+
+	err = writeConfigurationFile(baseName+"_configuration.yaml", downloadedArtifact.Configurations)
+	if err != nil {
+		return "", err
+	}
+
+	return baseName + ".zip", nil
+}
+
+//Save artifact configuration to YAML file
+func writeConfigurationFile(fileName string, configurations []*cpiclient.Configuration) error {
+	configurationFile := ConfigurationFileYAML{}
+	for _, configuration := range configurations {
+		configurationFile.Parameters = append(configurationFile.Parameters, ConfigurationParameterYAML{
+			Key:   configuration.ParameterKey,
+			Value: configuration.ParameterValue,
+			Type:  configuration.DataType,
+		})
+	}
+
+	content, err := yaml.Marshal(configurationFile)
+	if err != nil {
+		return err
+	}
 
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// deleteCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	return os.WriteFile(fileName, content, 0644)
 }
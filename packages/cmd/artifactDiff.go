@@ -0,0 +1,221 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Trifolium-project/landscaper/packages/iflow"
+	"github.com/Trifolium-project/landscaper/packages/landscape"
+	"github.com/spf13/cobra"
+)
+
+var diffTargetEnv *string
+var diffVersion *string
+var diffTargetVersion *string
+var diffOutput *string
+
+//Artifact version, which is compared
+type diffSide struct {
+	Environment string `json:"environment"`
+	Id          string `json:"id"`
+	Version     string `json:"version"`
+	PackageId   string `json:"packageId"`
+	content     *iflow.Artifact
+	secretKeys  map[string]bool
+}
+
+type artifactDiffResult struct {
+	Source *diffSide   `json:"source"`
+	Target *diffSide   `json:"target"`
+	Diff   *iflow.Diff `json:"diff"`
+}
+
+// artifactDiffCmd represents the artifact diff command
+var artifactDiffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare content of artifact between environments or versions",
+	Long: `Compare content of artifact between environments or versions.
+Artifact from --env is compared with the same artifact in --target-env, or with --target-version in the same environment.
+Changed steps and adapter properties of integration flow, changed scripts and resources, and differences of parameters are shown.
+Scripts and other text resources are shown as unified diff. Use --output json for machine readable output.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		artifactDiff()
+	},
+}
+
+func init() {
+	artifactCmd.AddCommand(artifactDiffCmd)
+
+	diffTargetEnv = artifactDiffCmd.Flags().String("target-env", "", "Environment to compare with, default is --env")
+	diffVersion = artifactDiffCmd.Flags().String("version", "active", "Version of artifact in --env")
+	diffTargetVersion = artifactDiffCmd.Flags().String("target-version", "active", "Version of artifact in target environment")
+	diffOutput = artifactDiffCmd.Flags().StringP("output", "o", "text", "Output format: text or json")
+}
+
+func artifactDiff() {
+	if globalLandscape == nil {
+		println("Global landscape is not instantiated")
+		return
+	}
+
+	if *artifact == "" {
+		println("Please specify artifact")
+		return
+	}
+
+	if *diffOutput != "text" && *diffOutput != "json" {
+		log.Fatalf("Unknown output format %s, use text or json\n", *diffOutput)
+	}
+
+	sourceEnv, err := globalLandscape.GetEnvironment(*environment)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	targetEnv := sourceEnv
+	if *diffTargetEnv != "" {
+		targetEnv, err = globalLandscape.GetEnvironment(*diffTargetEnv)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	if targetEnv == sourceEnv && *diffVersion == *diffTargetVersion {
+		log.Fatalln("Specify --target-env or --target-version to compare with")
+	}
+
+	//Artifact ID in --artifact already contains suffix of --env environment
+	source, err := readDiffSide(sourceEnv, *artifact, *diffVersion)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	target, err := readDiffSide(targetEnv, targetEnv.ArtifactId(sourceEnv.BaseArtifactId(*artifact)), *diffTargetVersion)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	result := &artifactDiffResult{Source: source, Target: target, Diff: iflow.Compare(source.content, target.content)}
+
+	//Values of parameters, declared as secrets in any of environments, are masked
+	for _, change := range result.Diff.Parameters {
+		sensitive := source.secretKeys[change.Key] || target.secretKeys[change.Key]
+		change.Source = landscape.MaskValue(change.Source, sensitive)
+		change.Target = landscape.MaskValue(change.Target, sensitive)
+	}
+
+	if *diffOutput == "json" {
+		content, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			log.Fatalln(err)
+		}
+		fmt.Println(string(content))
+		return
+	}
+
+	printArtifactDiff(result)
+}
+
+func readDiffSide(env *landscape.Environment, id string, version string) (*diffSide, error) {
+	downloadedArtifact, err := env.System.Client.DownloadIntegrationDesigntimeArtifact(id, version)
+	if err != nil {
+		return nil, fmt.Errorf("unable to download artifact %s version %s in environment %s: %s", id, version, env.Id, err)
+	}
+
+	content, err := iflow.FromArtifactContent(downloadedArtifact.ArtifactContent)
+	if err != nil {
+		return nil, fmt.Errorf("artifact %s in environment %s: %s", id, env.Id, err)
+	}
+
+	return &diffSide{
+		Environment: env.Id,
+		Id:          id,
+		Version:     downloadedArtifact.Version,
+		PackageId:   downloadedArtifact.PackageId,
+		content:     content,
+		secretKeys:  getSecretKeys(env, id, downloadedArtifact.PackageId),
+	}, nil
+}
+
+func printArtifactDiff(result *artifactDiffResult) {
+	fmt.Printf("Source: %s %s version %s\n", result.Source.Environment, result.Source.Id, result.Source.Version)
+	fmt.Printf("Target: %s %s version %s\n", result.Target.Environment, result.Target.Id, result.Target.Version)
+
+	diff := result.Diff
+	if diff.IsEmpty() {
+		fmt.Println("\nNo differences")
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+
+	if len(diff.Manifest) > 0 {
+		fmt.Fprintf(writer, "\n===Manifest===\n\n")
+		printPropertyChanges(writer, diff.Manifest)
+	}
+
+	if len(diff.Parameters) > 0 {
+		fmt.Fprintf(writer, "\n===Parameters===\n\n")
+		printPropertyChanges(writer, diff.Parameters)
+	}
+
+	if len(diff.Steps) > 0 {
+		fmt.Fprintf(writer, "\n===Steps===\n\n")
+		printElementChanges(writer, diff.Steps)
+	}
+
+	if len(diff.Adapters) > 0 {
+		fmt.Fprintf(writer, "\n===Adapters===\n\n")
+		printElementChanges(writer, diff.Adapters)
+	}
+
+	if len(diff.Files) > 0 {
+		fmt.Fprintf(writer, "\n===Files===\n\n")
+		fmt.Fprintf(writer, "Change\tPath\n")
+		for _, change := range diff.Files {
+			fmt.Fprintf(writer, "%s\t%s\n", change.Change, change.Path)
+		}
+	}
+	writer.Flush()
+
+	//Unified diffs are printed without alignment
+	for _, change := range diff.Files {
+		if change.Diff != "" {
+			fmt.Printf("\n%s", change.Diff)
+		}
+	}
+}
+
+func printPropertyChanges(writer *tabwriter.Writer, changes []*iflow.PropertyChange) {
+	fmt.Fprintf(writer, "Change\tKey\tSource\tTarget\n")
+	for _, change := range changes {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", change.Change, change.Key, valueOrDash(change.Source, change.Change != iflow.Added), valueOrDash(change.Target, change.Change != iflow.Removed))
+	}
+}
+
+func printElementChanges(writer *tabwriter.Writer, changes []*iflow.ElementChange) {
+	fmt.Fprintf(writer, "Change\tFlow\tId\tName\tProperty\tSource\tTarget\n")
+	for _, change := range changes {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t\t\t\n", change.Change, change.Flow, change.Id, change.Name)
+		for _, property := range change.Properties {
+			fmt.Fprintf(writer, "\t\t\t\t%s\t%s\t%s\n", property.Key, valueOrDash(property.Source, property.Change != iflow.Added), valueOrDash(property.Target, property.Change != iflow.Removed))
+		}
+	}
+}
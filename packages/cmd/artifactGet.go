@@ -21,6 +21,7 @@ import (
 	"os"
 	"text/tabwriter"
 
+	"github.com/Trifolium-project/landscaper/packages/landscape"
 	"github.com/spf13/cobra"
 )
 
@@ -90,8 +91,12 @@ func artifactGet() {
 
 	fmt.Fprintf(writer, "Key\tValue\tType\n")
 
+	//Values of parameters, declared as secrets in landscape, are masked
+	env, _ := globalLandscape.GetEnvironment(*environment)
+	secretKeys := getSecretKeys(env, artfct.Id, artfct.PackageId)
+
 	for _, configuration := range conf {
-		fmt.Fprintf(writer, "%s\t%s\t%s\n", configuration.ParameterKey, configuration.ParameterValue, configuration.DataType)
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", configuration.ParameterKey, landscape.MaskValue(configuration.ParameterValue, secretKeys[configuration.ParameterKey]), configuration.DataType)
 	}
 
 	//fmt.Fprintf(writer, "%d\t%s\t%s\n", index, pkg.Id, pkg.Name)
@@ -22,6 +22,7 @@ import (
 	"os"
 	"text/tabwriter"
 
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
 	"github.com/spf13/cobra"
 )
 
@@ -71,27 +72,30 @@ func artifactList() {
 		log.Fatalln(err)
 	}
 
+	//Runtime state of all artifacts is read with one request
+	runtimeArtifacts, err := system.Client.ReadIntegrationRuntimeArtifacts()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	runtimeArtifactsById := make(map[string]*cpiclient.IntegrationRuntimeArtifact)
+	for _, runtimeArtifact := range runtimeArtifacts {
+		runtimeArtifactsById[runtimeArtifact.Id] = runtimeArtifact
+	}
+
 	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
 	fmt.Fprintln(writer, "#\tArtefactId\tVersion\tPackage\tDeploy Status\tDeployed Version")
 	index := 0
 	for _, art := range artifacts {
-		status := "unknown"
+		status := "Not deployed"
 		deployedVersion := "-"
-		designtimeArtifact, err := system.Client.ReadIntegrationRuntimeArtifact(art.Id)
-		if err != nil {
-			//fmt.Println(err)
-			status = "Not deployed"
-			
-		} else {
-			status = designtimeArtifact.Status
-			deployedVersion = designtimeArtifact.Version
+		if runtimeArtifact, ok := runtimeArtifactsById[art.Id]; ok {
+			status = runtimeArtifact.Status
+			deployedVersion = runtimeArtifact.Version
 		}
 		if !(*onlyDeployed && status == "Not deployed") {
 			index++
 			fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\n", index, art.Id, art.Version, art.PackageId, status,deployedVersion)
 		}
-		
-		//fmt.Fprintf(writer, "%d\t%s\t%s\n", index, pkg.Id, pkg.Name)
 	}
 	writer.Flush()
 //
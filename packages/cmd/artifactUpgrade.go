@@ -16,35 +16,85 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/base64"
 	"fmt"
 	"log"
 	"os"
+	"path"
+	"sort"
+	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/landscape"
+	"github.com/Trifolium-project/landscaper/packages/util"
 	"github.com/spf13/cobra"
 )
 
 var template *string
 var writer *tabwriter.Writer
 var toDeployUpgraded *bool
+var upgradeIflowList *[]string
+var upgradeAllEnvs *bool
+var upgradeDryRun *bool
+var upgradeForce *bool
+var upgradeSkipUnknown *bool
+var upgradeBatch *int
+var upgradeTimeout *time.Duration
+
+//Files of artifact, which are different in every implementation and are not compared with template
+var implementationSpecificFiles = []string{"MANIFEST.MF", "parameters.prop", "metainfo.prop", ".project"}
+
+//Planned upgrade of template implementation
+type upgradePlan struct {
+	Environment    *landscape.Environment
+	Artifact       *landscape.Artifact
+	Id             string
+	Target         *cpiclient.IntegrationDesigntimeArtifact
+	Configurations []*cpiclient.Configuration
+	ModifiedFiles  []string
+	//Template version, from which implementation was built, is not known, so local modifications are not checked
+	BaseUnknown    bool
+	OnlyInTemplate []string
+	OnlyInTarget   []string
+	//Configuration cannot be mapped to new version of template
+	ConfigurationError error
+}
 
 // createCmd represents the create command
 var artifactUpgradeCmd = &cobra.Command{
 	Use:   "upgrade",
 	Short: "Upgrade artifact",
-	Long:  `Upgrade artifact`,
+	Long: `Upgrade implementations of template artifact to version of template in original environment.
+By default implementations in original environment are upgraded, use --env or --all-envs to upgrade copies in other environments.
+Configuration of implementation is preserved, configuration of environment from landscape file is applied on top of it.
+Implementations, which were modified after they had been created from template, are skipped unless --force is set.
+Template version and file hashes of upgraded implementation are recorded in landscape file and are used for this check on next upgrade.
+If they are not recorded and old version of template cannot be downloaded, modification state is unknown, such implementations
+are upgraded unless --skip-unknown is set.
+Use --dry-run to preview versions, configuration differences and local modifications without changes.
+
+Implementations are selected by --iflow(IDs or glob patterns of implementations in original environment) and --pkg.
+With --batch N implementations are upgraded in stages: N implementations are upgraded and deployed, and next stage
+is started only after all of them are in status STARTED.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		artifactUpgrade()
+		artifactUpgrade(cmd)
 	},
 }
 
 func init() {
 	artifactCmd.AddCommand(artifactUpgradeCmd)
 
-	iflowList = artifactUpgradeCmd.Flags().StringSliceP("iflow", "f", []string{}, "List of integration flows to upgrade")
+	upgradeIflowList = artifactUpgradeCmd.Flags().StringSliceP("iflow", "f", []string{}, "List of integration flows to upgrade, glob patterns are supported")
 	template = artifactUpgradeCmd.Flags().String("template", "", "Template iflow")
 	toDeployUpgraded = artifactUpgradeCmd.Flags().Bool("deploy", false, "Indicate whether necessary to deploy changed artifacts")
+	upgradeAllEnvs = artifactUpgradeCmd.Flags().Bool("all-envs", false, "Upgrade implementations in all environments")
+	upgradeDryRun = artifactUpgradeCmd.Flags().Bool("dry-run", false, "Show planned upgrade without changes")
+	upgradeForce = artifactUpgradeCmd.Flags().Bool("force", false, "Upgrade implementations, which were modified locally")
+	upgradeSkipUnknown = artifactUpgradeCmd.Flags().Bool("skip-unknown", false, "Skip implementations, whose template version is unknown")
+	upgradeBatch = artifactUpgradeCmd.Flags().Int("batch", 0, "Number of implementations, which are upgraded and deployed before waiting for their start")
+	upgradeTimeout = artifactUpgradeCmd.Flags().Duration("timeout", 10*time.Minute, "Maximum time to wait for start of deployed implementation")
 
 	artifactUpgradeCmd.MarkFlagRequired("template")	
 	// Here you will define your flags and configuration settings.
@@ -58,7 +108,7 @@ func init() {
 	// createCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
-func artifactUpgrade() {
+func artifactUpgrade(cmd *cobra.Command) {
 
 	//Perform intial checks
 	if globalLandscape == nil {
@@ -71,56 +121,460 @@ func artifactUpgrade() {
 		return
 	}
 
-	//Get list of iflows by template
-	artifactList := globalLandscape.GetArtifactsByTemplate(*template)
+	if *upgradeBatch < 0 {
+		log.Fatalln("Flag --batch should not be negative")
+	}
+	if *upgradeBatch > 0 && !*toDeployUpgraded && !*upgradeDryRun {
+		log.Fatalln("Staged rollout with --batch requires --deploy")
+	}
 
-	//TODO: If iflows are not empty, Get intersection of this list and iflows
+	environments, err := getUpgradeEnvironments(cmd)
+	if err != nil {
+		log.Fatalln(err)
+	}
 
-	writer = tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
-	fmt.Fprintf(writer, "#\tArtefactId\tVersion\tPackage\tUpgraded\tDeployed\n")
+	//Package ID in --pkg already contains suffix of --env environment
+	packageId := ""
+	if *pkg != "" {
+		env, err := globalLandscape.GetEnvironment(*environment)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		packageId = env.BasePackageId(*pkg)
+	}
 
-	client := globalLandscape.OriginalEnvironment.System.Client
-	sourceArtifact, err := client.ReadIntegrationDesigntimeArtifact(*template, "Active")
+	//Get list of iflows by template
+	artifactList, err := filterArtifacts(globalLandscape.GetArtifactsByTemplate(*template, packageId), *upgradeIflowList)
 	if err != nil {
 		log.Fatalln(err)
 	}
+	if len(artifactList) == 0 {
+		log.Fatalf("No implementations of template %s found in landscape", *template)
+	}
 
-	//Resulting list pass to the function, that moves artifacts (with version check, deploy logic and so on)
-	for index, artifact := range artifactList {
+	//Template is always taken from original environment
+	originalEnvironment := globalLandscape.OriginalEnvironment
+	sourceClient := originalEnvironment.System.Client
+	sourceArtifact, err := sourceClient.ReadIntegrationDesigntimeArtifact(originalEnvironment.ArtifactId(*template), "Active")
+	if err != nil {
+		log.Fatalln(err)
+	}
 
-		targetArtifact, err := client.ReadIntegrationDesigntimeArtifact(artifact.Id, "Active")
-		if err != nil {
-			log.Fatalln(err)
+	writer = tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+	if *upgradeDryRun {
+		fmt.Fprintf(writer, "#\tEnvironment\tArtefactId\tVersion\tTemplate version\tModified\tOnly in template\tOnly in implementation\tAction\n")
+	} else {
+		fmt.Fprintf(writer, "#\tEnvironment\tArtefactId\tVersion\tPackage\tModified\tUpgraded\tDeployed\n")
+	}
+
+	//All implementations are checked before any of them is changed
+	var plans []*upgradePlan
+	for _, env := range environments {
+		for _, artifact := range artifactList {
+			plan, err := planUpgrade(env, artifact, sourceArtifact)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			plans = append(plans, plan)
 		}
+	}
 
-		upgraded, err := upgradeArtifactVersion(sourceArtifact, targetArtifact)
-		if err != nil {
-			log.Fatalln(err)
+	if *upgradeDryRun {
+		for index, plan := range plans {
+			fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", index+1, plan.Environment.Id, plan.Id,
+				plan.version(), sourceArtifact.Version,
+				plan.modificationState(),
+				joinOrDash(plan.OnlyInTemplate),
+				joinOrDash(plan.OnlyInTarget),
+				plan.action(sourceArtifact))
+			for _, file := range plan.ModifiedFiles {
+				fmt.Fprintf(writer, "\t\t\t\t\t%s\t\t\t\n", file)
+			}
+		}
+		writer.Flush()
+		return
+	}
+
+	failed := false
+	for _, plan := range plans {
+		if plan.ConfigurationError != nil && !plan.skipped() {
+			log.Printf("%s cannot be upgraded: %s", plan.Id, plan.ConfigurationError)
+			failed = true
 		}
+	}
+	if failed {
+		log.Fatalln("Upgrade is cancelled, no implementations were changed")
+	}
+
+	//Resulting list pass to the function, that moves artifacts (with version check, deploy logic and so on)
+	index := 0
+	for _, env := range environments {
+		client := env.System.Client
+
+		//Implementations, which are deployed in current stage of rollout
+		var batch []string
+
+		for _, plan := range plans {
+			if plan.Environment != env {
+				continue
+			}
+			index++
+
+			if plan.Target == nil {
+				fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%t\n", index, env.Id, plan.Id, "-", env.PackageId(plan.Artifact.PackageId), "-", "not transported", false)
+				continue
+			}
+			if plan.skipped() {
+				if plan.BaseUnknown {
+					log.Printf("%s is not upgraded - template version %s, from which it was built, is unknown", plan.Id, plan.Target.Version)
+				} else {
+					log.Printf("%s is not upgraded - it was modified after version %s of template: %s", plan.Id, plan.Target.Version, strings.Join(plan.ModifiedFiles, ", "))
+				}
+				fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%t\n", index, env.Id, plan.Id, plan.Target.Version, plan.Target.PackageId, plan.modificationState(), "skipped", false)
+				continue
+			}
+			if plan.BaseUnknown && plan.Target.Version != sourceArtifact.Version {
+				log.Printf("Local modifications of %s are not checked - template version %s, from which it was built, is unknown", plan.Id, plan.Target.Version)
+			}
 
-		//Deploy
-		
-		if *toDeployUpgraded && upgraded {
-			err = client.DeployIntegrationDesigntimeArtifact(targetArtifact.Id, sourceArtifact.Version)
+			upgraded, err := upgradeArtifactVersion(sourceClient, client, sourceArtifact, plan.Target, plan.Configurations)
 			if err != nil {
 				log.Fatalln(err)
 			}
+
+			//Template version is recorded, so that next upgrade can find local modifications
+			if upgraded {
+				err = recordTemplateBase(env, plan.Artifact, sourceArtifact)
+				if err != nil {
+					log.Printf("Template version of %s is not recorded in landscape file: %s", plan.Id, err)
+				}
+			}
+
+			//Deploy
+
+			if *toDeployUpgraded && upgraded {
+				err = client.DeployIntegrationDesigntimeArtifact(plan.Target.Id, sourceArtifact.Version)
+				if err != nil {
+					log.Fatalln(err)
+				}
+			}
+			fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\t%t\t%t\n", index, env.Id, plan.Target.Id, sourceArtifact.Version, plan.Target.PackageId, plan.modificationState(), upgraded, *toDeployUpgraded && upgraded)
+
+			if *upgradeBatch > 0 && upgraded {
+				batch = append(batch, plan.Target.Id)
+				if len(batch) == *upgradeBatch {
+					waitForUpgradedArtifacts(client, batch, sourceArtifact.Version)
+					batch = nil
+				}
+			}
 		}
-		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%t\t%t\n", index+1, artifact.Id, sourceArtifact.Version, targetArtifact.PackageId, upgraded, *toDeploy && upgraded)
 
+		if len(batch) > 0 {
+			waitForUpgradedArtifacts(client, batch, sourceArtifact.Version)
+		}
 	}
 
 	writer.Flush()
 
 }
 
-//Recreate target artifact from source
-func upgradeArtifactVersion(sourceArtifact *cpiclient.IntegrationDesigntimeArtifact, targetArtifact *cpiclient.IntegrationDesigntimeArtifact) (bool, error) {
+//Read implementation in environment, compare it with template and prepare configuration
+func planUpgrade(env *landscape.Environment, artifact *landscape.Artifact, sourceArtifact *cpiclient.IntegrationDesigntimeArtifact) (*upgradePlan, error) {
+	plan := &upgradePlan{
+		Environment: env,
+		Artifact:    artifact,
+		Id:          env.ArtifactId(artifact.Id),
+	}
+
+	client := env.System.Client
+	targetArtifact, err := client.ReadIntegrationDesigntimeArtifact(plan.Id, "Active")
+	if cpiclient.IsNotFound(err) {
+		return plan, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	plan.Target = targetArtifact
+
+	plan.OnlyInTemplate, plan.OnlyInTarget = compareConfigurationKeys(sourceArtifact.Configurations, targetArtifact.Configurations)
+
+	//Content and configuration are checked only, if implementation is going to be upgraded
+	if targetArtifact.Version != sourceArtifact.Version {
+		plan.ModifiedFiles, plan.BaseUnknown, err = getLocalModifications(env, artifact, sourceArtifact, targetArtifact)
+		if err != nil {
+			return nil, err
+		}
+
+		plan.Configurations, plan.ConfigurationError = getUpgradeConfiguration(env, artifact, sourceArtifact, targetArtifact)
+	}
+
+	return plan, nil
+}
+
+func (plan *upgradePlan) version() string {
+	if plan.Target == nil {
+		return "-"
+	}
+	return plan.Target.Version
+}
+
+//Action, which is performed with implementation without --dry-run
+func (plan *upgradePlan) action(sourceArtifact *cpiclient.IntegrationDesigntimeArtifact) string {
+	switch {
+	case plan.Target == nil:
+		return "not transported"
+	case plan.Target.Version == sourceArtifact.Version:
+		return "up to date"
+	case plan.skipped() && plan.BaseUnknown:
+		return "skip(unknown)"
+	case plan.skipped():
+		return "skip(modified)"
+	case plan.ConfigurationError != nil:
+		return fmt.Sprintf("error: %s", plan.ConfigurationError)
+	default:
+		return "upgrade"
+	}
+}
+
+//Implementation was modified locally and is not upgraded without --force. Implementation with unknown template version
+//is skipped with --skip-unknown
+func (plan *upgradePlan) skipped() bool {
+	if plan.BaseUnknown {
+		return *upgradeSkipUnknown && !*upgradeForce
+	}
+	return len(plan.ModifiedFiles) > 0 && !*upgradeForce
+}
+
+//Whether implementation was modified after it had been built from template: true, false or unknown
+func (plan *upgradePlan) modificationState() string {
+	switch {
+	case plan.Target == nil:
+		return "-"
+	case plan.BaseUnknown:
+		return "unknown"
+	default:
+		return fmt.Sprintf("%t", len(plan.ModifiedFiles) > 0)
+	}
+}
+
+//Get configuration keys, which exist only in template, and keys, which exist only in implementation
+func compareConfigurationKeys(template []*cpiclient.Configuration, implementation []*cpiclient.Configuration) ([]string, []string) {
+	templateKeys := make(map[string]bool)
+	for _, conf := range template {
+		templateKeys[conf.ParameterKey] = true
+	}
+	implementationKeys := make(map[string]bool)
+	for _, conf := range implementation {
+		implementationKeys[conf.ParameterKey] = true
+	}
+
+	var onlyInTemplate, onlyInImplementation []string
+	for key := range templateKeys {
+		if !implementationKeys[key] {
+			onlyInTemplate = append(onlyInTemplate, key)
+		}
+	}
+	for key := range implementationKeys {
+		if !templateKeys[key] {
+			onlyInImplementation = append(onlyInImplementation, key)
+		}
+	}
+	sort.Strings(onlyInTemplate)
+	sort.Strings(onlyInImplementation)
+
+	return onlyInTemplate, onlyInImplementation
+}
+
+//Content of template versions, which are already downloaded
+var templateContentCache = map[string]map[string][]byte{}
+
+//Compare content of implementation with version of template, from which it was created. Returns list of changed files.
+//Template version is taken from landscape file, or downloaded from original environment. If neither is available,
+//template version is unknown
+func getLocalModifications(env *landscape.Environment, artifact *landscape.Artifact, sourceArtifact *cpiclient.IntegrationDesigntimeArtifact, targetArtifact *cpiclient.IntegrationDesigntimeArtifact) ([]string, bool, error) {
+	content, err := downloadArtifactContent(env.System.Client, targetArtifact.Id, targetArtifact.Version)
+	if err != nil {
+		return nil, false, err
+	}
+	implementationEntries, err := util.ReadZipEntries(content)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if base, ok := artifact.TemplateBases[env.Id]; ok && base.Version == targetArtifact.Version {
+		return base.ChangedFiles(normalizeArtifactEntries(implementationEntries)), false, nil
+	}
+
+	//Design time API usually provides only current version, so old version of template is often not found
+	templateEntries, err := getTemplateEntries(sourceArtifact.Id, targetArtifact.Version)
+	if cpiclient.IsNotFound(err) {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return changedArtifactEntries(templateEntries, implementationEntries), false, nil
+}
+
+//Download version of template from original environment
+func getTemplateEntries(templateId string, version string) (map[string][]byte, error) {
+	if entries, ok := templateContentCache[version]; ok {
+		return entries, nil
+	}
+
+	content, err := downloadArtifactContent(globalLandscape.OriginalEnvironment.System.Client, templateId, version)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := util.ReadZipEntries(content)
+	if err != nil {
+		return nil, err
+	}
+	templateContentCache[version] = entries
+
+	return entries, nil
+}
+
+//Write template version and file hashes of upgraded implementation to landscape file
+func recordTemplateBase(env *landscape.Environment, artifact *landscape.Artifact, sourceArtifact *cpiclient.IntegrationDesigntimeArtifact) error {
+	templateEntries, err := getTemplateEntries(sourceArtifact.Id, sourceArtifact.Version)
+	if err != nil {
+		return err
+	}
+	base := landscape.NewTemplateBase(sourceArtifact.Version, normalizeArtifactEntries(templateEntries))
+	return globalLandscape.SetTemplateBase(artifact.PackageId, artifact.Id, env.Id, base)
+}
+
+//Files, which differ in two versions of artifact content. Implementation specific files are ignored
+func changedArtifactEntries(sourceEntries map[string][]byte, targetEntries map[string][]byte) []string {
+	source := normalizeArtifactEntries(sourceEntries)
+	target := normalizeArtifactEntries(targetEntries)
+
+	var modified []string
+	for name, data := range target {
+		if sourceData, ok := source[name]; !ok || string(sourceData) != string(data) {
+			modified = append(modified, name)
+		}
+	}
+	for name := range source {
+		if _, ok := target[name]; !ok {
+			modified = append(modified, name)
+		}
+	}
+	sort.Strings(modified)
+
+	return modified
+}
+
+//Download artifact and decode its content
+func downloadArtifactContent(client *cpiclient.CPIClient, id string, version string) ([]byte, error) {
+	downloadedArtifact, err := client.DownloadIntegrationDesigntimeArtifact(id, version)
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(downloadedArtifact.ArtifactContent)
+}
+
+//Remove implementation specific files. Integration flow file is named after artifact, so only its directory is kept
+func normalizeArtifactEntries(entries map[string][]byte) map[string][]byte {
+	normalized := make(map[string][]byte)
+	for name, data := range entries {
+		if util.Contains(implementationSpecificFiles, path.Base(name)) {
+			continue
+		}
+		if path.Ext(name) == ".iflw" {
+			name = path.Join(path.Dir(name), "*.iflw")
+		}
+		normalized[name] = data
+	}
+	return normalized
+}
+
+func joinOrDash(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ",")
+}
+
+//Keep artifacts, which match IDs or glob patterns. All artifacts are kept, if list is empty
+func filterArtifacts(artifacts []*landscape.Artifact, patterns []string) ([]*landscape.Artifact, error) {
+	if len(patterns) == 0 {
+		return artifacts, nil
+	}
+
+	var filtered []*landscape.Artifact
+	for _, artifact := range artifacts {
+		for _, pattern := range patterns {
+			matched, err := path.Match(pattern, artifact.Id)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %s", pattern, err)
+			}
+			if matched {
+				filtered = append(filtered, artifact)
+				break
+			}
+		}
+	}
+
+	return filtered, nil
+}
+
+//Wait until all deployed implementations of stage are started, rollout is stopped otherwise
+func waitForUpgradedArtifacts(client *cpiclient.CPIClient, ids []string, version string) {
+	log.Printf("Waiting for start of %s...", strings.Join(ids, ", "))
+	for _, id := range ids {
+		err := client.WaitForIntegrationRuntimeArtifactStatus(id, version, "STARTED", *upgradeTimeout)
+		if err != nil {
+			writer.Flush()
+			log.Fatalf("Rollout is stopped: %s", err)
+		}
+	}
+}
+
+//Get environments, where implementations are upgraded: original environment by default, environment from --env flag, or all environments
+func getUpgradeEnvironments(cmd *cobra.Command) ([]*landscape.Environment, error) {
+	if *upgradeAllEnvs && cmd.Flag("env").Changed {
+		return nil, fmt.Errorf("flags --env and --all-envs cannot be used together")
+	}
+
+	originalEnvironment := globalLandscape.OriginalEnvironment
+	if *upgradeAllEnvs {
+		return append([]*landscape.Environment{originalEnvironment}, selectEnvironments(cmd)...), nil
+	}
+
+	env, err := globalLandscape.GetEnvironment(*environment)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*landscape.Environment{env}, nil
+}
+
+//Configuration of upgraded implementation - its own values with parameter mapping applied, overridden by configuration
+//of environment from landscape. New parameters of template get default values from mapping
+func getUpgradeConfiguration(env *landscape.Environment, artifact *landscape.Artifact, sourceArtifact *cpiclient.IntegrationDesigntimeArtifact, targetArtifact *cpiclient.IntegrationDesigntimeArtifact) ([]*cpiclient.Configuration, error) {
+	configurations := artifact.ParameterMapping.Apply(targetArtifact.Configurations)
+
+	declared, _ := globalLandscape.GetArtifactConfiguration(env.Id, artifact.PackageId, artifact.Id)
+	declared, err := landscape.ResolveParameters(declared)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve configuration of %s: %s", targetArtifact.Id, err)
+	}
+
+	configurations = artifact.ParameterMapping.Merge(configurations, declared)
+
+	return artifact.ParameterMapping.Complete(configurations, sourceArtifact.Configurations)
+}
+
+//Recreate target artifact from source. Source and target can be located in different systems
+func upgradeArtifactVersion(sourceClient *cpiclient.CPIClient, targetClient *cpiclient.CPIClient, sourceArtifact *cpiclient.IntegrationDesigntimeArtifact, targetArtifact *cpiclient.IntegrationDesigntimeArtifact, configurations []*cpiclient.Configuration) (bool, error) {
 
 	//Check version
 	//TODO: Ensure that version is fetched as "Active", when iflow is in draft state
 	if sourceArtifact.Version == "Active" {
-		log.Fatalf("Artifact %s is in Draft state. Please save it as version.", sourceArtifact.Id)
+		return false, fmt.Errorf("artifact %s is in Draft state. Please save it as version", sourceArtifact.Id)
 	}
 
 	if sourceArtifact.Version == targetArtifact.Version {
@@ -128,21 +582,11 @@ func upgradeArtifactVersion(sourceArtifact *cpiclient.IntegrationDesigntimeArtif
 		return false, nil
 	}
 
-	//Save configurations
-	//targetArtifact.Configurations, err = globalLandscape.OriginalEnvironment.System.Client.ReadIntegrationDesigntimeArtifactConfigurations(targetArtifact.Id, "Active")
-	//if err != nil {
-	//	log.Fatalln(err)
-	//}
-
-	//Delete target integration flow
-
-	globalLandscape.OriginalEnvironment.System.Client.DeleteIntegrationDesigntimeArtifact(targetArtifact.Id, targetArtifact.Version)
+	//Download source iflow before target is deleted
 
-	//Download source iflow
-
-	newArtifact, err := globalLandscape.OriginalEnvironment.System.Client.DownloadIntegrationDesigntimeArtifact(sourceArtifact.Id, sourceArtifact.Version)
+	newArtifact, err := sourceClient.DownloadIntegrationDesigntimeArtifact(sourceArtifact.Id, sourceArtifact.Version)
 	if err != nil {
-		log.Fatalln(err)
+		return false, err
 	}
 	newArtifact.Name = targetArtifact.Name
 	newArtifact.PackageId = targetArtifact.PackageId
@@ -151,23 +595,24 @@ func upgradeArtifactVersion(sourceArtifact *cpiclient.IntegrationDesigntimeArtif
 	newArtifact.Receiver = targetArtifact.Receiver
 	newArtifact.Sender = targetArtifact.Sender
 
+	//Delete target integration flow
+
+	err = targetClient.DeleteIntegrationDesigntimeArtifact(targetArtifact.Id, targetArtifact.Version)
+	if err != nil {
+		return false, err
+	}
+
 	//Upgrade version from source
-	err = globalLandscape.OriginalEnvironment.System.Client.UploadIntegrationDesigntimeArtifact(newArtifact)
+	err = targetClient.UploadIntegrationDesigntimeArtifact(newArtifact)
 	if err != nil {
-		log.Fatalln(err)
+		return false, err
 	}
 
 	//Update configurations
-	for _, config := range targetArtifact.Configurations {
-		conf := &cpiclient.Configuration{
-			ParameterKey:   config.ParameterKey,
-			ParameterValue: config.ParameterValue,
-			DataType:       config.DataType,
-		}
-
-		err = globalLandscape.OriginalEnvironment.System.Client.UpdateIntegrationDesigntimeArtifactConfiguration(newArtifact.Id, newArtifact.Version, conf)
+	for _, conf := range configurations {
+		err = targetClient.UpdateIntegrationDesigntimeArtifactConfiguration(newArtifact.Id, newArtifact.Version, conf)
 		if err != nil {
-			log.Fatalln(err)
+			return false, err
 		}
 	}
 
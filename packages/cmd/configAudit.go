@@ -0,0 +1,153 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/landscape"
+	"github.com/spf13/cobra"
+)
+
+// configAuditCmd represents the audit command
+var configAuditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Check declared configuration against artifacts in tenant",
+	Long: `Compare configuration, declared in landscape file, with configuration keys and data types of artifact version in tenant.
+Keys, which are not found in artifact, values, which do not match data type(e.g. "yes" for xsd:boolean),
+and parameters, which differ from original environment, but are not declared, are reported.
+All environments are checked, unless --env is set. Use --pkg and --artifact to check selected artifacts only.
+Command exits with non-zero code, if any issue is reported. Artifacts, which are not transported to environment, are not counted as issues.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		configAudit(cmd)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configAuditCmd)
+}
+
+func configAudit(cmd *cobra.Command) {
+	if globalLandscape == nil {
+		println("Global landscape is not instantiated")
+		return
+	}
+
+	//Package and artifact IDs in flags already contain suffix of --env environment
+	flagEnvironment, err := globalLandscape.GetEnvironment(*environment)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	basePackageId := ""
+	if *pkg != "" {
+		basePackageId = flagEnvironment.BasePackageId(*pkg)
+	}
+	baseArtifactId := ""
+	if *artifact != "" {
+		baseArtifactId = flagEnvironment.BaseArtifactId(*artifact)
+	}
+
+	environments := selectEnvironments(cmd)
+	if !cmd.Flag("env").Changed {
+		environments = append([]*landscape.Environment{globalLandscape.OriginalEnvironment}, environments...)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "#\tEnvironment\tArtifactId\tVersion\tKey\tIssue\tDetails")
+
+	index := 0
+	failed := false
+	for _, env := range environments {
+		for _, pkgId := range sortedPackageIds(globalLandscape) {
+			if basePackageId != "" && pkgId != basePackageId {
+				continue
+			}
+			pkgObj := globalLandscape.Packages[pkgId]
+
+			for _, artifactId := range sortedArtifactIds(pkgObj) {
+				if baseArtifactId != "" && artifactId != baseArtifactId {
+					continue
+				}
+				id := env.ArtifactId(artifactId)
+
+				version, findings, err := auditArtifactConfiguration(env, pkgId, artifactId)
+				if cpiclient.IsNotFound(err) {
+					index++
+					fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", index, env.Id, id, "-", "-", "NOT TRANSPORTED", "-")
+					continue
+				}
+				if err != nil {
+					log.Fatalln(err)
+				}
+
+				for _, finding := range findings {
+					index++
+					failed = true
+					fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", index, env.Id, id, version, finding.Key, finding.Issue, finding.Details)
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+
+	if index == 0 {
+		fmt.Println("No configuration issues found")
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+//Audit declared configuration of artifact against its active version in environment
+func auditArtifactConfiguration(env *landscape.Environment, pkgId string, artifactId string) (string, []*landscape.AuditFinding, error) {
+	id := env.ArtifactId(artifactId)
+	client := env.System.Client
+
+	designtimeArtifact, err := client.ReadIntegrationDesigntimeArtifact(id, "active")
+	if err != nil {
+		return "", nil, err
+	}
+	tenantConfigurations, err := client.ReadIntegrationDesigntimeArtifactConfigurations(id, designtimeArtifact.Version)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to read configuration of %s: %s", id, err)
+	}
+
+	declared, _ := globalLandscape.GetArtifactConfiguration(env.Id, pkgId, artifactId)
+
+	//Undeclared differences are checked only for copies of original environment.
+	//If original configuration cannot be read, they cannot be checked, which is reported as well
+	var originalConfigurations []*cpiclient.Configuration
+	var originalFinding *landscape.AuditFinding
+	if env != globalLandscape.OriginalEnvironment {
+		originalConfigurations, err = readOriginalConfigurations(artifactId)
+		if err != nil {
+			originalFinding = &landscape.AuditFinding{Key: "-", Issue: landscape.AuditOriginalUnavailable, Details: err.Error()}
+		}
+	}
+
+	findings := landscape.AuditConfiguration(declared, tenantConfigurations, originalConfigurations)
+	if originalFinding != nil {
+		findings = append(findings, originalFinding)
+	}
+
+	return designtimeArtifact.Version, findings, nil
+}
@@ -17,17 +17,23 @@ package cmd
 
 import (
 	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
 
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/landscape"
 	"github.com/spf13/cobra"
 )
 
 // readCmd represents the read command
 var configReadCmd = &cobra.Command{
 	Use:   "read",
-	Short: "Read config(not implemented)",
-	Long: `Read config(not implemented)`,
+	Short: "Read config and compare it with landscape",
+	Long: `Read artifact configuration in tenant and compare it with value, declared in landscape file,
+and with value in original environment. Drifted parameters are marked in State column.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("read called(Not implemented)")
+		configRead()
 	},
 }
 
@@ -44,3 +50,114 @@ func init() {
 	// is called directly, e.g.:
 	// readCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
+
+func configRead() {
+	if globalLandscape == nil {
+		println("Global landscape is not instantiated")
+		return
+	}
+
+	currentEnvironment, err := globalLandscape.GetEnvironment(*environment)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	originalEnvironment := globalLandscape.OriginalEnvironment
+
+	artfct, err := currentEnvironment.System.Client.ReadIntegrationDesigntimeArtifact(*artifact, "active")
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	states, err := readConfigurationStates(currentEnvironment, artfct.Id, artfct.PackageId, artfct.Configurations)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+	fmt.Fprintf(writer, "===Artifact metadata===\n\n")
+
+	fmt.Fprintf(writer, "%s\t%s\n", "ID:", artfct.Id)
+	fmt.Fprintf(writer, "%s\t%s\n", "Name:", artfct.Name)
+	fmt.Fprintf(writer, "%s\t%s\n", "Version:", artfct.Version)
+	fmt.Fprintf(writer, "%s\t%s\n", "Package:", artfct.PackageId)
+
+	fmt.Fprintf(writer, "\n===Configuration===\n\n")
+	fmt.Fprintf(writer, "Key\tTenant(%s)\tLandscape(%s)\tOriginal(%s)\tType\tState\n", currentEnvironment.Id, currentEnvironment.Id, originalEnvironment.Id)
+
+	for _, state := range states {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n", state.Key,
+			valueOrDash(landscape.MaskValue(state.TenantValue, state.Sensitive), state.InTenant),
+			valueOrDash(landscape.MaskValue(state.DeclaredValue, state.Sensitive), state.Declared),
+			valueOrDash(landscape.MaskValue(state.OriginalValue, state.Sensitive), state.InOriginal),
+			state.Type,
+			driftState(state))
+	}
+
+	writer.Flush()
+}
+
+//Compare tenant configuration of artifact with landscape definition and original environment
+func readConfigurationStates(env *landscape.Environment, artifactId string, packageId string, tenantConfigurations []*cpiclient.Configuration) ([]*landscape.ParameterState, error) {
+	originalEnvironment := globalLandscape.OriginalEnvironment
+
+	baseArtifactId := env.BaseArtifactId(artifactId)
+	basePackageId := env.BasePackageId(packageId)
+
+	declared, _ := globalLandscape.GetArtifactConfiguration(env.Id, basePackageId, baseArtifactId)
+	declared, err := landscape.ResolveParameters(declared)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve configuration of %s: %s", artifactId, err)
+	}
+
+	originalConfigurations := tenantConfigurations
+	if env != originalEnvironment {
+		originalConfigurations, err = readOriginalConfigurations(baseArtifactId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return landscape.CompareConfiguration(tenantConfigurations, declared, originalConfigurations), nil
+}
+
+//Configurations of artifacts in original environment, which are already read
+var originalConfigurationCache = map[string][]*cpiclient.Configuration{}
+
+//Read configuration of artifact in original environment
+func readOriginalConfigurations(artifactId string) ([]*cpiclient.Configuration, error) {
+	if configurations, ok := originalConfigurationCache[artifactId]; ok {
+		return configurations, nil
+	}
+
+	configurations, err := globalLandscape.OriginalEnvironment.System.Client.ReadIntegrationDesigntimeArtifactConfigurations(artifactId, "active")
+	if err != nil {
+		return nil, fmt.Errorf("unable to read configuration of %s in original environment: %s", artifactId, err)
+	}
+	originalConfigurationCache[artifactId] = configurations
+
+	return configurations, nil
+}
+
+//Keys of parameters, which are declared as secrets in landscape for artifact in environment
+func getSecretKeys(env *landscape.Environment, artifactId string, packageId string) map[string]bool {
+	declared, _ := globalLandscape.GetArtifactConfiguration(env.Id, env.BasePackageId(packageId), env.BaseArtifactId(artifactId))
+	return landscape.SecretKeys(declared)
+}
+
+func valueOrDash(value string, exists bool) string {
+	if !exists {
+		return "-"
+	}
+	return value
+}
+
+func driftState(state *landscape.ParameterState) string {
+	switch {
+	case !state.InTenant:
+		return "MISSING"
+	case state.Drifted:
+		return "DRIFT"
+	default:
+		return "ok"
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/Trifolium-project/landscaper/packages/landscape"
+	"github.com/spf13/cobra"
+)
+
+var renderResolved *bool
+
+// configRenderCmd represents the render command
+var configRenderCmd = &cobra.Command{
+	Use:   "render",
+	Short: "Print merged landscape definition",
+	Long: `Merge main landscape file with files from include section and landscape.d directory, and print the result.
+Duplicate systems, environments, packages and artifacts are reported as conflicts.
+Use --resolved to merge package defaults into artifacts and replace variables with values of environments.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		configRender()
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configRenderCmd)
+
+	renderResolved = configRenderCmd.Flags().Bool("resolved", false, "Resolve package defaults and variables")
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// configRenderCmd.PersistentFlags().String("foo", "", "A help for foo")
+}
+
+func configRender() {
+	landscapeYAML, _, err := landscape.ReadLandscapeYAML(getLandscapeFilePath())
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	header := "#Merged landscape definition of " + getLandscapeFilePath()
+	if *renderResolved {
+		landscapeYAML, err = landscape.ResolveLandscapeYAML(landscapeYAML)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		header = "#Resolved landscape definition of " + getLandscapeFilePath()
+	}
+
+	content, err := encodeLandscapeYAML(landscapeYAML, header)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	os.Stdout.Write(content)
+}
@@ -23,16 +23,38 @@ import (
 	"text/tabwriter"
 
 	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/landscape"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var configurations *[]string
+var configFromLandscape *bool
+var configParametersFile *string
+var toDeployConfigured *bool
+var configIgnoreUnknown *bool
+
+//Bulk configuration file, artifact IDs are the same as in original environment
+type ConfigurationUpdateFileYAML struct {
+	Artifacts []struct {
+		Id         string                       `yaml:"id"`
+		Parameters []ConfigurationParameterYAML `yaml:"parameters"`
+	} `yaml:"artifacts"`
+}
+
+//Configuration parameters, which should be applied to artifact
+type artifactConfigurationUpdate struct {
+	ArtifactId string
+	Parameters []*landscape.Parameter
+}
 
 // updateCmd represents the update command
 var configUpdateCmd = &cobra.Command{
 	Use:   "update",
-	Short: "Update config(not implemented)",
-	Long:  `Read config(not implemented)`,
+	Short: "Update config",
+	Long: `Update configuration of artifact with key:value pairs, or apply configuration in bulk mode:
+from landscape file(--from-landscape) or from parameters file(--file).
+In bulk mode keys, which are not found in artifact, fail the command, unless --ignore-unknown is set.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		configUpdate()
 	},
@@ -43,6 +65,10 @@ func init() {
 
 
 	configurations = configUpdateCmd.Flags().StringSliceP("config", "f", []string{}, "List of configuration key:value pairs")
+	configFromLandscape = configUpdateCmd.Flags().Bool("from-landscape", false, "Apply configuration, declared in landscape file for environment, to all artifacts")
+	configParametersFile = configUpdateCmd.Flags().String("file", "", "Path to YAML file with parameters for multiple artifacts")
+	toDeployConfigured = configUpdateCmd.Flags().BoolP("deploy", "d", false, "Indicate whether necessary to redeploy changed artifacts")
+	configIgnoreUnknown = configUpdateCmd.Flags().Bool("ignore-unknown", false, "Do not fail in bulk mode, if key is not found in artifact")
 	
 	// Here you will define your flags and configuration settings.
 
@@ -62,22 +88,18 @@ func configUpdate() {
 		return
 	}
 
-	system, err := globalLandscape.GetSystem4Environment(environment)
+	if *configFromLandscape || *configParametersFile != "" {
+		configUpdateBulk()
+		return
+	}
+
+	currentEnvironment, err := globalLandscape.GetEnvironment(*environment)
 	if err != nil {
 		log.Fatalln(err)
 	}
-	
-	/*
-	TODO: Add suffix to artefact ID
-	environmentObject, _ := globalLandscape.GetEnvironment(*environment)
-	aftifactId := ""
+	system := currentEnvironment.System
 
-	if environmentObject.Suffix == "" {
-		aftifactId = *artifact
-	} else {
-		aftifactId = *artifact + environmentObject.Suffix
-	}
-	*/
+	//Suffix of environment is already added to artifact ID in initConfig
 
 	//Check if this artifact exists, and print it's details
 	artfct, err := system.Client.ReadIntegrationDesigntimeArtifact(*artifact, "Active")
@@ -95,13 +117,25 @@ func configUpdate() {
 
 	//Get and print current config
 	conf, err := system.Client.ReadIntegrationDesigntimeArtifactConfigurations(*artifact, "Active")
+	if err != nil {
+		log.Fatalln(err)
+	}
 	fmt.Fprintf(writer, "\n===Old Configuration===\n\n")
 
 	fmt.Fprintf(writer, "Key\tValue\tType\n")
 
+	//Values of parameters, declared as secrets in landscape or passed as secret references, are masked
+	secretKeys := getSecretKeys(currentEnvironment, artfct.Id, artfct.PackageId)
+	for _, newConfiguration := range *configurations {
+		confTuple := strings.SplitN(newConfiguration, ":", 2)
+		if len(confTuple) == 2 && landscape.IsSecretReference(confTuple[1]) {
+			secretKeys[confTuple[0]] = true
+		}
+	}
+
 	for _, oldConfiguration := range conf {
 		fmt.Fprintf(writer, "%s\t%s\t%s\n", oldConfiguration.ParameterKey, 
-											oldConfiguration.ParameterValue, 
+											landscape.MaskValue(oldConfiguration.ParameterValue, secretKeys[oldConfiguration.ParameterKey]), 
 											oldConfiguration.DataType)
 	}
 
@@ -121,10 +155,19 @@ func configUpdate() {
 			log.Fatalln(err)
 		}
 
+		value := confTuple[1]
+		if landscape.IsSecretReference(value) {
+			value, err = landscape.ResolveSecret(value)
+			if err != nil {
+				log.Fatalf("Unable to resolve parameter %s: %s", confTuple[0], err)
+			}
+		}
+
 		newConf := &cpiclient.Configuration{
 			ParameterKey:   confTuple[0],
-			ParameterValue: confTuple[1],
+			ParameterValue: value,
 			DataType:       dataType,
+			Sensitive:      secretKeys[confTuple[0]],
 		}
 
 		newConfigurations = append(newConfigurations, newConf)
@@ -136,17 +179,24 @@ func configUpdate() {
 
 	for _, newConfiguration := range newConfigurations {
 		err = system.Client.UpdateIntegrationDesigntimeArtifactConfiguration(*artifact, "Active", newConfiguration)
+		if err != nil {
+			writer.Flush()
+			log.Fatalf("Unable to update parameter %s: %s", newConfiguration.ParameterKey, err)
+		}
 	}
 
 	//Read configuration after change
 	conf, err = system.Client.ReadIntegrationDesigntimeArtifactConfigurations(*artifact, "Active")
+	if err != nil {
+		log.Fatalln(err)
+	}
 	fmt.Fprintf(writer, "\n===New Configuration===\n\n")
 
 	fmt.Fprintf(writer, "Key\tValue\tType\n")
 
 	for _, configuration := range conf {
 		fmt.Fprintf(writer, "%s\t%s\t%s\n", configuration.ParameterKey, 
-											configuration.ParameterValue, 
+											landscape.MaskValue(configuration.ParameterValue, secretKeys[configuration.ParameterKey]), 
 											configuration.DataType)
 	}
 
@@ -155,13 +205,190 @@ func configUpdate() {
 
 }
 
-//Get configuration type(xsd:string, xsd:boolean, custom:schedule etc.)
-func getConfigurationType(configurationKey string, configurations []*cpiclient.Configuration) (string, error) {
-	
-	for _, configuration := range configurations{
+//Apply configuration to multiple artifacts and report result of each parameter update
+func configUpdateBulk() {
+	currentEnvironment, err := globalLandscape.GetEnvironment(*environment)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	client := currentEnvironment.System.Client
+
+	var updates []*artifactConfigurationUpdate
+	if *configParametersFile != "" {
+		updates, err = readConfigurationUpdateFile(*configParametersFile)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	} else {
+		updates = getLandscapeConfigurationUpdates(currentEnvironment)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "#\tArtifactId\tKey\tOld value\tNew value\tResult")
+
+	index := 0
+	failed := false
+	var changedArtifacts []string
+	for _, update := range updates {
+		id := currentEnvironment.ArtifactId(update.ArtifactId)
+
+		//Filter by --artifact flag
+		if *artifact != "" && *artifact != id {
+			continue
+		}
+
+		tenantConfigurations, err := client.ReadIntegrationDesigntimeArtifactConfigurations(id, "active")
+		if err != nil {
+			index++
+			result := fmt.Sprintf("failed: %s", err)
+			if cpiclient.IsNotFound(err) {
+				result = "artifact not found"
+			}
+			fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\n", index, id, "-", "-", "-", result)
+			continue
+		}
+
+		//Secret references are resolved only before update
+		parameters, err := landscape.ResolveParameters(update.Parameters)
+		if err != nil {
+			index++
+			fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\n", index, id, "-", "-", "-", fmt.Sprintf("failed: %s", err))
+			failed = true
+			continue
+		}
+
+		changed := false
+		for _, parameter := range parameters {
+			tenantConfiguration, err := getConfiguration(parameter.Key, tenantConfigurations)
+			//Package defaults are applied only to artifacts, which have such parameter
+			if err != nil && parameter.Inherited {
+				continue
+			}
+
+			index++
+			oldValue := "-"
+			result := ""
+
+			switch {
+			case err != nil:
+				result = "unknown key"
+				failed = failed || !*configIgnoreUnknown
+			case tenantConfiguration.ParameterValue == parameter.Value:
+				oldValue = tenantConfiguration.ParameterValue
+				result = "unchanged"
+			default:
+				oldValue = tenantConfiguration.ParameterValue
+				err = client.UpdateIntegrationDesigntimeArtifactConfiguration(id, "active", &cpiclient.Configuration{
+					ParameterKey:   parameter.Key,
+					ParameterValue: parameter.Value,
+					DataType:       tenantConfiguration.DataType,
+					Sensitive:      parameter.Sensitive,
+				})
+				if err != nil {
+					result = fmt.Sprintf("failed: %s", err)
+					failed = true
+				} else {
+					result = "updated"
+					changed = true
+				}
+			}
+			fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\n", index, id, parameter.Key,
+				landscape.MaskValue(oldValue, parameter.Sensitive && oldValue != "-"),
+				landscape.MaskValue(parameter.Value, parameter.Sensitive),
+				result)
+		}
+
+		if changed {
+			changedArtifacts = append(changedArtifacts, id)
+		}
+	}
+	writer.Flush()
+
+	if *toDeployConfigured {
+		for _, id := range changedArtifacts {
+			err = client.DeployIntegrationDesigntimeArtifact(id, "active")
+			if err != nil {
+				log.Printf("Unable to deploy %s: %s", id, err)
+				failed = true
+				continue
+			}
+			fmt.Printf("Deploy of %s started\n", id)
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+//Collect declared configuration of environment from landscape. Only package from --pkg flag is used, if it is set
+func getLandscapeConfigurationUpdates(env *landscape.Environment) []*artifactConfigurationUpdate {
+	var updates []*artifactConfigurationUpdate
+
+	for _, pkgId := range sortedPackageIds(globalLandscape) {
+		if *pkg != "" && env.PackageId(pkgId) != *pkg {
+			continue
+		}
+		pkgObj := globalLandscape.Packages[pkgId]
+		for _, artifactId := range sortedArtifactIds(pkgObj) {
+			configuration, ok := pkgObj.Artifacts[artifactId].Configurations[env.Id]
+			if !ok || len(configuration.Parameters) == 0 {
+				continue
+			}
+			updates = append(updates, &artifactConfigurationUpdate{
+				ArtifactId: artifactId,
+				Parameters: configuration.Parameters,
+			})
+		}
+	}
+
+	return updates
+}
+
+//Read bulk configuration file
+func readConfigurationUpdateFile(fileName string) ([]*artifactConfigurationUpdate, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	file := ConfigurationUpdateFileYAML{}
+	err = yaml.Unmarshal(content, &file)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse parameters file %s: %s", fileName, err)
+	}
+
+	var updates []*artifactConfigurationUpdate
+	for _, artifactYAML := range file.Artifacts {
+		update := &artifactConfigurationUpdate{ArtifactId: artifactYAML.Id}
+		for _, parameterYAML := range artifactYAML.Parameters {
+			update.Parameters = append(update.Parameters, &landscape.Parameter{
+				Key:   parameterYAML.Key,
+				Value: parameterYAML.Value,
+				Type:  parameterYAML.Type,
+			})
+		}
+		updates = append(updates, update)
+	}
+
+	return updates, nil
+}
+
+//Get configuration by key
+func getConfiguration(configurationKey string, configurations []*cpiclient.Configuration) (*cpiclient.Configuration, error) {
+	for _, configuration := range configurations {
 		if configuration.ParameterKey == configurationKey {
-			return configuration.DataType, nil
+			return configuration, nil
 		}
 	}
-	return "", fmt.Errorf("configuration key %s is not found", configurationKey)
+	return nil, fmt.Errorf("configuration key %s is not found", configurationKey)
+}
+
+//Get configuration type(xsd:string, xsd:boolean, custom:schedule etc.)
+func getConfigurationType(configurationKey string, configurations []*cpiclient.Configuration) (string, error) {
+	configuration, err := getConfiguration(configurationKey, configurations)
+	if err != nil {
+		return "", err
+	}
+	return configuration.DataType, nil
 }
\ No newline at end of file
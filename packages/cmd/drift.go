@@ -0,0 +1,191 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/landscape"
+	"github.com/spf13/cobra"
+)
+
+var adoptDrift *bool
+var enforceDrift *bool
+
+// driftCmd represents the drift command
+var driftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Detect configuration drift",
+	Long: `Compare configuration of every artifact from landscape file in every environment with declared configuration.
+Parameters, that are not declared in landscape file, are compared with original environment.
+Use --adopt to write tenant values into landscape file, or --enforce to push declared values to tenant.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		drift(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(driftCmd)
+
+	adoptDrift = driftCmd.Flags().Bool("adopt", false, "Write values from tenant into landscape file")
+	enforceDrift = driftCmd.Flags().Bool("enforce", false, "Push declared values to tenant")
+
+	// Cobra supports Persistent Flags which will work for this command
+	// and all subcommands, e.g.:
+	// driftCmd.PersistentFlags().String("foo", "", "A help for foo")
+}
+
+func drift(cmd *cobra.Command) {
+	if globalLandscape == nil {
+		println("Global landscape is not instantiated")
+		return
+	}
+
+	if *adoptDrift && *enforceDrift {
+		log.Fatalln("Flags --adopt and --enforce cannot be used together")
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "#\tEnvironment\tArtifactId\tKey\tTenant\tExpected\tState\tAction")
+
+	index := 0
+	for _, env := range selectEnvironments(cmd) {
+		for _, pkgId := range sortedPackageIds(globalLandscape) {
+			pkgObj := globalLandscape.Packages[pkgId]
+
+			for _, artifactId := range sortedArtifactIds(pkgObj) {
+				id := env.ArtifactId(artifactId)
+				client := env.System.Client
+
+				tenantConfigurations, err := client.ReadIntegrationDesigntimeArtifactConfigurations(id, "active")
+				if cpiclient.IsNotFound(err) {
+					index++
+					fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", index, env.Id, id, "-", "-", "-", "NOT TRANSPORTED", "-")
+					continue
+				}
+				if err != nil {
+					log.Fatalln(err)
+				}
+
+				states, err := readConfigurationStates(env, id, env.PackageId(pkgId), tenantConfigurations)
+				if err != nil {
+					log.Fatalln(err)
+				}
+
+				var adopted []*landscape.Parameter
+				for _, state := range states {
+					if !state.Drifted {
+						continue
+					}
+
+					action := "-"
+					//Secret values are never written to landscape file
+					if state.InTenant && *adoptDrift && state.Sensitive {
+						action = "skipped(secret)"
+					}
+					if state.InTenant && *adoptDrift && !state.Sensitive {
+						adopted = append(adopted, &landscape.Parameter{
+							Key:   state.Key,
+							Value: state.TenantValue,
+							Type:  state.Type,
+						})
+						action = "adopted"
+					}
+					if state.InTenant && *enforceDrift {
+						err = client.UpdateIntegrationDesigntimeArtifactConfiguration(id, "active", &cpiclient.Configuration{
+							ParameterKey:   state.Key,
+							ParameterValue: state.ExpectedValue(),
+							DataType:       state.Type,
+							Sensitive:      state.Sensitive,
+						})
+						if err != nil {
+							log.Printf("Unable to update parameter %s of %s: %s", state.Key, id, err)
+							action = "failed"
+						} else {
+							action = "enforced"
+						}
+					}
+
+					index++
+					fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", index, env.Id, id, state.Key,
+						valueOrDash(landscape.MaskValue(state.TenantValue, state.Sensitive), state.InTenant),
+						landscape.MaskValue(state.ExpectedValue(), state.Sensitive),
+						driftState(state),
+						action)
+				}
+
+				if len(adopted) > 0 {
+					err = globalLandscape.SetArtifactParameters(pkgId, artifactId, env.Id, adopted)
+					if err != nil {
+						log.Fatalln(err)
+					}
+				}
+			}
+		}
+	}
+
+	writer.Flush()
+
+	if index == 0 {
+		fmt.Println("No configuration drift found")
+	}
+}
+
+//Get environment from --env flag, or all environments except original one
+func selectEnvironments(cmd *cobra.Command) []*landscape.Environment {
+	if cmd.Flag("env").Changed {
+		env, err := globalLandscape.GetEnvironment(*environment)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		return []*landscape.Environment{env}
+	}
+
+	var environments []*landscape.Environment
+	for _, env := range globalLandscape.Environments {
+		if env != globalLandscape.OriginalEnvironment {
+			environments = append(environments, env)
+		}
+	}
+	sort.Slice(environments, func(i, j int) bool {
+		return environments[i].Id < environments[j].Id
+	})
+
+	return environments
+}
+
+func sortedPackageIds(landscape_ *landscape.Landscape) []string {
+	var ids []string
+	for id := range landscape_.Packages {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func sortedArtifactIds(pkgObj *landscape.Package) []string {
+	var ids []string
+	for id := range pkgObj.Artifacts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,332 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/landscape"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var initSystems *[]string
+var initSuffixes *[]string
+var initOutput *string
+var initName *string
+var initForce *bool
+
+//Packages and artifacts, read from system
+type scannedSystem struct {
+	System    *landscape.System
+	Packages  map[string]*cpiclient.IntegrationPackage
+	Artifacts map[string]map[string]*cpiclient.IntegrationDesigntimeArtifact
+}
+
+// initCmd represents the init command
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Generate landscape file from existing tenants",
+	Long: `Scan packages and artifacts of existing tenants, detect environment suffixes and generate landscape file.
+Only parameters, which differ from original environment, are added to landscape file.
+
+Systems are passed as id=host, e.g. --system dev=xxx-tmn.hci.eu1.hana.ondemand.com. Credentials are read from
+environment variables <ID>_LOGIN_ENV_VAR and <ID>_PASSWORD_ENV_VAR. First system hosts original environment.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		landscapeInit()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+
+	initSystems = initCmd.Flags().StringSlice("system", []string{}, "System to scan as id=host, or id of system from existing landscape file")
+	initSuffixes = initCmd.Flags().StringSlice("suffix", []string{}, "List of environment suffixes(detected automatically, if not set)")
+	initOutput = initCmd.Flags().String("out", "", "Path to generated landscape file(default is --landscape-file)")
+	initName = initCmd.Flags().String("name", "Generated landscape", "Landscape name")
+	initForce = initCmd.Flags().Bool("force", false, "Overwrite existing landscape file")
+
+	initCmd.MarkFlagRequired("system")
+}
+
+func landscapeInit() {
+	output := *initOutput
+	if output == "" {
+		output = getLandscapeFilePath()
+	}
+	if _, err := os.Stat(output); err == nil && !*initForce {
+		log.Fatalf("Landscape file %s already exists, use --force to overwrite it", output)
+	}
+
+	landscapeYAML := &landscape.LandscapeYAML{}
+	landscapeYAML.Landscape.Name = *initName
+
+	var scannedSystems []*scannedSystem
+	var ids []string
+	for _, systemSpec := range *initSystems {
+		system, systemYAML, err := getInitSystem(systemSpec)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		landscapeYAML.Landscape.Systems = append(landscapeYAML.Landscape.Systems, systemYAML)
+
+		log.Printf("Scanning system %s...", system.Id)
+		scanned, err := scanSystem(system)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		scannedSystems = append(scannedSystems, scanned)
+
+		for packageId, artifacts := range scanned.Artifacts {
+			ids = append(ids, packageId)
+			for artifactId := range artifacts {
+				ids = append(ids, artifactId)
+			}
+		}
+	}
+
+	suffixes := *initSuffixes
+	if len(suffixes) == 0 {
+		suffixes = landscape.DetectSuffixes(ids)
+	}
+
+	//Environments - one without suffix for every system, and one for each suffix, found in system
+	environmentSystems := make(map[string]*scannedSystem)
+	for _, scanned := range scannedSystems {
+		environmentYAML := landscape.EnvironmentYAML{
+			Id:     getEnvironmentId(capitalize(scanned.System.Id), landscapeYAML),
+			Name:   scanned.System.Name,
+			System: scanned.System.Id,
+		}
+		landscapeYAML.Landscape.Environments = append(landscapeYAML.Landscape.Environments, environmentYAML)
+		environmentSystems[environmentYAML.Id] = scanned
+
+		for _, suffix := range suffixes {
+			if !hasSuffixedPackages(scanned, suffix, suffixes) {
+				continue
+			}
+			environmentYAML := landscape.EnvironmentYAML{
+				Id:     getEnvironmentId(suffix, landscapeYAML),
+				Name:   suffix + " Environment",
+				Suffix: suffix,
+				System: scanned.System.Id,
+			}
+			landscapeYAML.Landscape.Environments = append(landscapeYAML.Landscape.Environments, environmentYAML)
+			environmentSystems[environmentYAML.Id] = scanned
+		}
+	}
+
+	original := scannedSystems[0]
+	landscapeYAML.Landscape.OriginalEnvironment = landscapeYAML.Landscape.Environments[0].Id
+
+	//Packages of original environment with parameters, which differ in other environments
+	for _, packageId := range sortedKeys(original.Packages) {
+		base, suffix := landscape.SplitSuffix(packageId, suffixes)
+		if _, ok := original.Packages[base]; suffix != "" && ok {
+			continue
+		}
+
+		packageYAML := landscape.PackageYAML{Id: packageId}
+		originalArtifacts := original.Artifacts[packageId]
+
+		var artifactIds []string
+		for artifactId := range originalArtifacts {
+			artifactIds = append(artifactIds, artifactId)
+		}
+		sort.Strings(artifactIds)
+
+		for _, artifactId := range artifactIds {
+			artifactYAML := landscape.ArtifactYAML{Id: artifactId}
+
+			for _, environmentYAML := range landscapeYAML.Landscape.Environments[1:] {
+				scanned := environmentSystems[environmentYAML.Id]
+				env := landscape.NewEnvironment(environmentYAML, nil)
+				targetArtifact, ok := scanned.Artifacts[env.PackageId(packageId)][env.ArtifactId(artifactId)]
+				if !ok {
+					continue
+				}
+
+				parameters := landscape.DiffParameters(targetArtifact.Configurations, originalArtifacts[artifactId].Configurations)
+				if len(parameters) == 0 {
+					continue
+				}
+				artifactYAML.Configurations = append(artifactYAML.Configurations, landscape.ConfigurationYAML{
+					Environment: environmentYAML.Id,
+					Parameters:  parameters,
+				})
+			}
+
+			packageYAML.Artifacts = append(packageYAML.Artifacts, artifactYAML)
+		}
+
+		landscapeYAML.Landscape.Packages = append(landscapeYAML.Landscape.Packages, packageYAML)
+	}
+
+	err := writeLandscapeYAML(output, landscapeYAML, "#Landscape definition generated by landscaper init")
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "#\tEnvironment\tSystem\tSuffix")
+	for index, environmentYAML := range landscapeYAML.Landscape.Environments {
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\n", index+1, environmentYAML.Id, environmentYAML.System, environmentYAML.Suffix)
+	}
+	writer.Flush()
+
+	fmt.Printf("\nLandscape definition with %d packages is written to %s\n", len(landscapeYAML.Landscape.Packages), output)
+}
+
+//Get system by id=host specification, or from existing landscape file
+func getInitSystem(systemSpec string) (*landscape.System, landscape.SystemYAML, error) {
+	split := strings.SplitN(systemSpec, "=", 2)
+	id := split[0]
+	if id == "" {
+		return nil, landscape.SystemYAML{}, fmt.Errorf("system ID is empty in %s, please use --system id=host", systemSpec)
+	}
+
+	if len(split) == 1 {
+		if globalLandscape == nil || globalLandscape.Systems[id] == nil {
+			return nil, landscape.SystemYAML{}, fmt.Errorf("system %s is not found in landscape file, please use --system %s=host", id, id)
+		}
+		system := globalLandscape.Systems[id]
+		return system, landscape.SystemYAML{
+			Id:       system.Id,
+			Name:     system.Name,
+			Host:     system.Host,
+			Login:    system.LoginVariable,
+			Password: system.PasswordVariable,
+		}, nil
+	}
+
+	variablePrefix := strings.ToUpper(strings.ReplaceAll(id, "-", "_"))
+	systemYAML := landscape.SystemYAML{
+		Id:       id,
+		Name:     id,
+		Host:     split[1],
+		Login:    variablePrefix + "_LOGIN_ENV_VAR",
+		Password: variablePrefix + "_PASSWORD_ENV_VAR",
+	}
+	system, err := landscape.NewSystem(systemYAML)
+
+	return system, systemYAML, err
+}
+
+//Read packages and artifacts with configuration
+func scanSystem(system *landscape.System) (*scannedSystem, error) {
+	scanned := &scannedSystem{
+		System:    system,
+		Packages:  make(map[string]*cpiclient.IntegrationPackage),
+		Artifacts: make(map[string]map[string]*cpiclient.IntegrationDesigntimeArtifact),
+	}
+
+	packages, err := system.Client.ReadIntegrationPackages()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pkgObj := range packages {
+		artifacts, err := system.Client.ReadIntegrationDesigntimeArtifacts(pkgObj.Id, true)
+		if err != nil {
+			return nil, err
+		}
+
+		scanned.Packages[pkgObj.Id] = pkgObj
+		scanned.Artifacts[pkgObj.Id] = make(map[string]*cpiclient.IntegrationDesigntimeArtifact)
+		for _, art := range artifacts {
+			scanned.Artifacts[pkgObj.Id][art.Id] = art
+		}
+	}
+
+	return scanned, nil
+}
+
+//Check, whether system contains copies of packages with suffix
+func hasSuffixedPackages(scanned *scannedSystem, suffix string, suffixes []string) bool {
+	for packageId := range scanned.Packages {
+		_, packageSuffix := landscape.SplitSuffix(packageId, suffixes)
+		if packageSuffix == suffix {
+			return true
+		}
+	}
+	return false
+}
+
+//Environment ID should be unique, system ID is added in case of conflict
+func getEnvironmentId(id string, landscapeYAML *landscape.LandscapeYAML) string {
+	for _, environmentYAML := range landscapeYAML.Landscape.Environments {
+		if environmentYAML.Id == id {
+			return getEnvironmentId(id+"_"+landscapeYAML.Landscape.Systems[len(landscapeYAML.Landscape.Systems)-1].Id, landscapeYAML)
+		}
+	}
+	return id
+}
+
+//Environment ID from system ID, e.g. Dev for dev
+func capitalize(value string) string {
+	if value == "" {
+		return value
+	}
+	return strings.ToUpper(value[:1]) + value[1:]
+}
+
+func sortedKeys(packages map[string]*cpiclient.IntegrationPackage) []string {
+	var keys []string
+	for key := range packages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+//Write landscape definition to file
+func writeLandscapeYAML(fileName string, landscapeYAML *landscape.LandscapeYAML, header string) error {
+	content, err := encodeLandscapeYAML(landscapeYAML, header)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(fileName), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(fileName, content, 0644)
+}
+
+//Serialize landscape definition with header comment
+func encodeLandscapeYAML(landscapeYAML *landscape.LandscapeYAML, header string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	buffer.WriteString(header + "\n")
+
+	encoder := yaml.NewEncoder(buffer)
+	encoder.SetIndent(2)
+	err := encoder.Encode(landscapeYAML)
+	if err != nil {
+		return nil, err
+	}
+	encoder.Close()
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,253 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"text/tabwriter"
+
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/iflow"
+	"github.com/Trifolium-project/landscaper/packages/landscape"
+	"github.com/spf13/cobra"
+)
+
+var lintIflowList *[]string
+var lintFailOn *string
+var lintOutput *string
+var lintListRules *bool
+
+//Lint result of one artifact
+type lintResult struct {
+	ArtifactId string           `json:"artifactId"`
+	Version    string           `json:"version"`
+	Findings   []*iflow.Finding `json:"findings"`
+	//Artifact cannot be downloaded or parsed
+	Error string `json:"error,omitempty"`
+}
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check integration flows with static rules",
+	Long: `Check content of integration flows with static rules, e.g. hard-coded endpoints, credentials, which are not externalized,
+debug log level, missing exception subprocess, payload logging in scripts and unused parameters.
+Artifact from --artifact, artifacts of package from --pkg, or artifacts of all packages from landscape file in --env are checked.
+Severity of rules, exclusions and severity, which fails lint, are configured in lint section of landscape file.
+Use --list-rules to show available rules.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		lint()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+
+	lintIflowList = lintCmd.Flags().StringSliceP("iflow", "f", []string{}, "List of integration flows to check, glob patterns are supported")
+	lintFailOn = lintCmd.Flags().String("fail-on", "", "Minimal severity, which fails lint: error, warning or info. Default is taken from landscape file")
+	lintOutput = lintCmd.Flags().StringP("output", "o", "text", "Output format: text or json")
+	lintListRules = lintCmd.Flags().Bool("list-rules", false, "Show available rules")
+}
+
+func lint() {
+	if *lintListRules {
+		printLintRules()
+		return
+	}
+
+	if globalLandscape == nil {
+		println("Global landscape is not instantiated")
+		return
+	}
+
+	if *lintOutput != "text" && *lintOutput != "json" {
+		log.Fatalf("Unknown output format %s, use text or json\n", *lintOutput)
+	}
+
+	config := getLintConfig(*lintFailOn)
+
+	env, err := globalLandscape.GetEnvironment(*environment)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	artifacts, err := getLintArtifacts(env)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	results := lintDesigntimeArtifacts(env, artifacts, config)
+
+	if *lintOutput == "json" {
+		content, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			log.Fatalln(err)
+		}
+		fmt.Println(string(content))
+	} else {
+		printLintResults(results)
+	}
+
+	if lintFailed(results, config) {
+		log.Fatalf("Lint failed: findings with severity %s or higher are found\n", config.FailOn)
+	}
+}
+
+//Lint settings of landscape file. Severity, which fails lint, can be overridden
+func getLintConfig(failOn string) *iflow.LintConfig {
+	config := &iflow.LintConfig{FailOn: iflow.SeverityError}
+	if globalLandscape != nil && globalLandscape.Lint != nil {
+		copied := *globalLandscape.Lint
+		config = &copied
+	}
+	if failOn != "" {
+		if err := iflow.ValidateSeverity(failOn, false); err != nil {
+			log.Fatalln(err)
+		}
+		config.FailOn = failOn
+	}
+	return config
+}
+
+//Artifacts from --artifact, --pkg, or all packages of landscape file in environment
+func getLintArtifacts(env *landscape.Environment) ([]*cpiclient.IntegrationDesigntimeArtifact, error) {
+	client := env.System.Client
+
+	if *artifact != "" {
+		designtimeArtifact, err := client.ReadIntegrationDesigntimeArtifact(*artifact, "active")
+		if err != nil {
+			return nil, err
+		}
+		return []*cpiclient.IntegrationDesigntimeArtifact{designtimeArtifact}, nil
+	}
+
+	var packageIds []string
+	if *pkg != "" {
+		packageIds = []string{*pkg}
+	} else {
+		for _, packageId := range sortedPackageIds(globalLandscape) {
+			packageIds = append(packageIds, env.PackageId(packageId))
+		}
+	}
+
+	var artifacts []*cpiclient.IntegrationDesigntimeArtifact
+	for _, packageId := range packageIds {
+		packageArtifacts, err := client.ReadIntegrationDesigntimeArtifacts(packageId, false)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read artifacts of package %s: %s", packageId, err)
+		}
+		for _, packageArtifact := range packageArtifacts {
+			matched, err := matchesAnyPattern(packageArtifact.Id, *lintIflowList)
+			if err != nil {
+				return nil, err
+			}
+			if matched {
+				artifacts = append(artifacts, packageArtifact)
+			}
+		}
+	}
+	return artifacts, nil
+}
+
+//Download and check artifacts. Exclusions of rules use artifact IDs of original environment
+func lintDesigntimeArtifacts(env *landscape.Environment, artifacts []*cpiclient.IntegrationDesigntimeArtifact, config *iflow.LintConfig) []*lintResult {
+	var results []*lintResult
+	for _, designtimeArtifact := range artifacts {
+		result := &lintResult{ArtifactId: designtimeArtifact.Id, Version: designtimeArtifact.Version}
+		results = append(results, result)
+
+		downloadedArtifact, err := env.System.Client.DownloadIntegrationDesigntimeArtifact(designtimeArtifact.Id, designtimeArtifact.Version)
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		content, err := iflow.FromArtifactContent(downloadedArtifact.ArtifactContent)
+		if err != nil {
+			result.Error = err.Error()
+			continue
+		}
+		result.Findings = iflow.Lint(content, env.BaseArtifactId(designtimeArtifact.Id), config)
+	}
+	return results
+}
+
+//Lint fails, if artifact cannot be checked, or findings have severity from config
+func lintFailed(results []*lintResult, config *iflow.LintConfig) bool {
+	for _, result := range results {
+		if result.Error != "" || config.Failed(result.Findings) {
+			return true
+		}
+	}
+	return false
+}
+
+func printLintResults(results []*lintResult) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+	fmt.Fprintf(writer, "#\tArtifactId\tVersion\tRule\tSeverity\tLocation\tMessage\n")
+
+	counts := make(map[string]int)
+	index := 0
+	for _, result := range results {
+		if result.Error != "" {
+			index++
+			fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", index, result.ArtifactId, result.Version, "-", iflow.SeverityError, "-", result.Error)
+			counts[iflow.SeverityError]++
+			continue
+		}
+		for _, finding := range result.Findings {
+			index++
+			fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", index, result.ArtifactId, result.Version, finding.Rule, finding.Severity, finding.Location, finding.Message)
+			counts[finding.Severity]++
+		}
+	}
+	writer.Flush()
+
+	fmt.Printf("\nChecked %d artifacts: %d errors, %d warnings, %d info\n", len(results), counts[iflow.SeverityError], counts[iflow.SeverityWarning], counts[iflow.SeverityInfo])
+}
+
+func printLintRules() {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+	fmt.Fprintf(writer, "Rule\tSeverity\tDescription\n")
+	for _, rule := range iflow.Rules {
+		var config *iflow.LintConfig
+		if globalLandscape != nil {
+			config = globalLandscape.Lint
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", rule.Id, config.Severity(rule), rule.Description)
+	}
+	writer.Flush()
+}
+
+//ID matches any of glob patterns. Any ID matches empty list
+func matchesAnyPattern(id string, patterns []string) (bool, error) {
+	if len(patterns) == 0 {
+		return true, nil
+	}
+	for _, pattern := range patterns {
+		matched, err := path.Match(pattern, id)
+		if err != nil {
+			return false, fmt.Errorf("invalid pattern %s: %s", pattern, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
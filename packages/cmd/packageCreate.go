@@ -17,34 +17,219 @@ package cmd
 
 import (
 	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
 
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/landscape"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
+//Package definition, which can be read from YAML or JSON file
+type PackageDescriptor struct {
+	Id             string   `yaml:"id" json:"id"`
+	Name           string   `yaml:"name" json:"name"`
+	ShortText      string   `yaml:"shortText" json:"shortText"`
+	Description    string   `yaml:"description" json:"description"`
+	Vendor         string   `yaml:"vendor" json:"vendor"`
+	Version        string   `yaml:"version" json:"version"`
+	Keywords       []string `yaml:"keywords" json:"keywords"`
+	Products       []string `yaml:"products" json:"products"`
+	Countries      []string `yaml:"countries" json:"countries"`
+	Industries     []string `yaml:"industries" json:"industries"`
+	LineOfBusiness []string `yaml:"lineOfBusiness" json:"lineOfBusiness"`
+}
+
+var packageDescriptorFile *string
+var createInAllEnvs *bool
+var packageDescriptorFlags *PackageDescriptor
+
 // createCmd represents the create command
 var packageCreateCmd = &cobra.Command{
 	Use:   "create",
-	Short: "Create integration package(not implemented)",
-	Long: `Create integration package(not implemented)`,
+	Short: "Create integration package",
+	Long: `Create integration package from flags or from YAML/JSON descriptor.
+If package already exists, its metadata is updated.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		packageCreate()
+		packageCreate(cmd)
 	},
 }
 
 func init() {
 	packageCmd.AddCommand(packageCreateCmd)
 
-	// Here you will define your flags and configuration settings.
+	packageDescriptorFlags = &PackageDescriptor{}
+
+	packageDescriptorFile = packageCreateCmd.Flags().String("file", "", "Path to YAML/JSON package descriptor")
+	createInAllEnvs = packageCreateCmd.Flags().Bool("all-envs", false, "Create package in every environment of the landscape")
+
+	packageCreateCmd.Flags().StringVar(&packageDescriptorFlags.Name, "name", "", "Package name")
+	packageCreateCmd.Flags().StringVar(&packageDescriptorFlags.ShortText, "short-text", "", "Package short text")
+	packageCreateCmd.Flags().StringVar(&packageDescriptorFlags.Description, "description", "", "Package description")
+	packageCreateCmd.Flags().StringVar(&packageDescriptorFlags.Vendor, "vendor", "", "Package vendor")
+	packageCreateCmd.Flags().StringVar(&packageDescriptorFlags.Version, "version", "", "Package version")
+	packageCreateCmd.Flags().StringSliceVar(&packageDescriptorFlags.Keywords, "keywords", []string{}, "List of keywords")
+	packageCreateCmd.Flags().StringSliceVar(&packageDescriptorFlags.Products, "products", []string{}, "List of products")
+	packageCreateCmd.Flags().StringSliceVar(&packageDescriptorFlags.Countries, "countries", []string{}, "List of countries")
+	packageCreateCmd.Flags().StringSliceVar(&packageDescriptorFlags.Industries, "industries", []string{}, "List of industries")
+	packageCreateCmd.Flags().StringSliceVar(&packageDescriptorFlags.LineOfBusiness, "lob", []string{}, "List of lines of business")
 
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
 	// createCmd.PersistentFlags().String("foo", "", "A help for foo")
+}
+
+func packageCreate(cmd *cobra.Command) {
+	if globalLandscape == nil {
+		println("Global landscape is not instantiated")
+		return
+	}
+
+	currentEnvironment, err := globalLandscape.GetEnvironment(*environment)
+	if err != nil {
+		log.Fatalln(err)
+	}
 
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// createCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	descriptor := &PackageDescriptor{}
+	if *packageDescriptorFile != "" {
+		descriptor, err = readPackageDescriptor(*packageDescriptorFile)
+		if err != nil {
+			log.Fatalln(err)
+		}
+	}
+
+	//Flags have priority over descriptor file
+	if *pkg != "" {
+		descriptor.Id = currentEnvironment.BasePackageId(*pkg)
+	}
+	mergePackageDescriptorFlags(cmd, descriptor)
+
+	if descriptor.Id == "" {
+		log.Fatalln("Package ID is not provided, please use --pkg flag or id in descriptor file")
+	}
+	if descriptor.Name == "" {
+		descriptor.Name = descriptor.Id
+	}
+
+	environments := []*landscape.Environment{currentEnvironment}
+	if *createInAllEnvs {
+		environments = []*landscape.Environment{}
+		for _, env := range globalLandscape.Environments {
+			environments = append(environments, env)
+		}
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "#\tEnvironment\tPackageId\tName\tResult")
+
+	for index, env := range environments {
+		integrationPackage, created, err := createOrUpdatePackage(env, descriptor)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		result := "updated"
+		if created {
+			result = "created"
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\n", index+1, env.Id, integrationPackage.Id, integrationPackage.Name, result)
+	}
+
+	writer.Flush()
 }
 
-func packageCreate(){
-	fmt.Println("create called(not implemented)")
-}
\ No newline at end of file
+//Create package in environment, or update metadata if it already exists
+func createOrUpdatePackage(env *landscape.Environment, descriptor *PackageDescriptor) (*cpiclient.IntegrationPackage, bool, error) {
+
+	integrationPackage := newIntegrationPackage(env, descriptor)
+
+	_, err := env.System.Client.ReadIntegrationPackage(integrationPackage.Id)
+	if cpiclient.IsNotFound(err) {
+		err = env.System.Client.CreateIntegrationPackage(integrationPackage)
+		return integrationPackage, true, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	err = env.System.Client.UpdateIntegrationPackage(integrationPackage)
+	return integrationPackage, false, err
+}
+
+//Package metadata in environment, which follows naming convention of environment
+func newIntegrationPackage(env *landscape.Environment, descriptor *PackageDescriptor) *cpiclient.IntegrationPackage {
+	integrationPackage := &cpiclient.IntegrationPackage{
+		Id:             env.PackageId(descriptor.Id),
+		Name:           descriptor.Name,
+		ShortText:      descriptor.ShortText,
+		Description:    descriptor.Description,
+		Vendor:         descriptor.Vendor,
+		Version:        descriptor.Version,
+		Keywords:       strings.Join(descriptor.Keywords, ","),
+		Products:       strings.Join(descriptor.Products, ","),
+		Countries:      strings.Join(descriptor.Countries, ","),
+		Industries:     strings.Join(descriptor.Industries, ","),
+		LineOfBusiness: strings.Join(descriptor.LineOfBusiness, ","),
+	}
+
+	if env != globalLandscape.OriginalEnvironment {
+		integrationPackage.Name = env.PackageName(descriptor.Name)
+		integrationPackage.ShortText = env.PackageShortText(descriptor.ShortText)
+	}
+
+	return integrationPackage
+}
+
+//Read package descriptor. JSON is a subset of YAML, so both formats are supported
+func readPackageDescriptor(fileName string) (*PackageDescriptor, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptor := &PackageDescriptor{}
+	err = yaml.Unmarshal(content, descriptor)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse package descriptor %s: %s", fileName, err)
+	}
+
+	return descriptor, nil
+}
+
+//Overwrite descriptor values with explicitly passed flags
+func mergePackageDescriptorFlags(cmd *cobra.Command, descriptor *PackageDescriptor) {
+	flags := cmd.Flags()
+
+	if flags.Changed("name") {
+		descriptor.Name = packageDescriptorFlags.Name
+	}
+	if flags.Changed("short-text") {
+		descriptor.ShortText = packageDescriptorFlags.ShortText
+	}
+	if flags.Changed("description") {
+		descriptor.Description = packageDescriptorFlags.Description
+	}
+	if flags.Changed("vendor") {
+		descriptor.Vendor = packageDescriptorFlags.Vendor
+	}
+	if flags.Changed("version") {
+		descriptor.Version = packageDescriptorFlags.Version
+	}
+	if flags.Changed("keywords") {
+		descriptor.Keywords = packageDescriptorFlags.Keywords
+	}
+	if flags.Changed("products") {
+		descriptor.Products = packageDescriptorFlags.Products
+	}
+	if flags.Changed("countries") {
+		descriptor.Countries = packageDescriptorFlags.Countries
+	}
+	if flags.Changed("industries") {
+		descriptor.Industries = packageDescriptorFlags.Industries
+	}
+	if flags.Changed("lob") {
+		descriptor.LineOfBusiness = packageDescriptorFlags.LineOfBusiness
+	}
+}
@@ -0,0 +1,277 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/landscape"
+	"github.com/spf13/cobra"
+)
+
+//Design-time artifact with its runtime state
+type bulkArtifact struct {
+	Id        string
+	Type      string
+	PackageId string
+	Version   string
+	//Runtime artifact, nil if artifact is not deployed
+	Runtime *cpiclient.IntegrationRuntimeArtifact
+}
+
+//Filters of artifacts for bulk deploy and undeploy
+type bulkFilter struct {
+	patterns     *[]string
+	artifactType *string
+}
+
+var deployFilter *bulkFilter
+var deployAllPackages *bool
+var deployOnlyChanged *bool
+var deployOnlyNotDeployed *bool
+var deployErrors *bool
+var deployWait *bool
+var deployTimeout *time.Duration
+
+// packageDeployCmd represents the package deploy command
+var packageDeployCmd = &cobra.Command{
+	Use:   "deploy",
+	Short: "Deploy artifacts of package or environment",
+	Long: `Deploy integration flows and value mappings of package from --pkg, or of all packages of --env environment with --all.
+Value mappings are deployed and started before integration flows, which can depend on them.
+Artifacts can be selected by glob pattern, type and state: changed(deployed version differs from design time), not deployed,
+or in status ERROR. State filters can be combined, artifact is deployed, if it matches any of them.
+Runtime status of every artifact is awaited, and summary is printed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		packageDeploy()
+	},
+}
+
+func init() {
+	packageCmd.AddCommand(packageDeployCmd)
+
+	deployFilter = addBulkFilterFlags(packageDeployCmd)
+	deployAllPackages = packageDeployCmd.Flags().Bool("all", false, "Deploy artifacts of all packages of environment, if --pkg is not set")
+	deployOnlyChanged = packageDeployCmd.Flags().Bool("only-changed", false, "Deploy artifacts, which are not deployed, or whose deployed version differs from design time")
+	deployOnlyNotDeployed = packageDeployCmd.Flags().Bool("only-not-deployed", false, "Deploy artifacts, which are not deployed")
+	deployErrors = packageDeployCmd.Flags().Bool("redeploy-errors", false, "Redeploy artifacts in status ERROR")
+	deployWait = packageDeployCmd.Flags().Bool("wait", true, "Wait for runtime status of deployed artifacts")
+	deployTimeout = packageDeployCmd.Flags().Duration("timeout", 10*time.Minute, "Maximum time to wait for start of every artifact")
+}
+
+func addBulkFilterFlags(cmd *cobra.Command) *bulkFilter {
+	return &bulkFilter{
+		patterns:     cmd.Flags().StringSliceP("artifacts", "f", []string{}, "List of artifacts, glob patterns are supported"),
+		artifactType: cmd.Flags().String("type", "", "Type of artifacts: iflow or value-mapping. All types by default"),
+	}
+}
+
+func packageDeploy() {
+	if globalLandscape == nil {
+		println("Global landscape is not instantiated")
+		return
+	}
+
+	if *pkg == "" && !*deployAllPackages {
+		log.Fatalln("Package is not provided, please use --pkg flag, or --all to deploy all packages of environment")
+	}
+
+	currentEnvironment, err := globalLandscape.GetEnvironment(*environment)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	artifacts, err := getBulkArtifacts(currentEnvironment, deployFilter)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	var selected []*bulkArtifact
+	for _, bulkArtifact := range artifacts {
+		if matchesDeployState(bulkArtifact) {
+			selected = append(selected, bulkArtifact)
+		}
+	}
+	if len(selected) == 0 {
+		fmt.Println("No artifacts to deploy")
+		return
+	}
+
+	client := currentEnvironment.System.Client
+	results := make(map[*bulkArtifact]string)
+	failed := 0
+
+	//Value mappings are started before integration flows
+	for _, artifactType := range []string{artifactTypeValueMapping, artifactTypeIflow} {
+		var triggered []*bulkArtifact
+		for _, bulkArtifact := range selected {
+			if bulkArtifact.Type != artifactType {
+				continue
+			}
+			if err := deployImportedArtifact(client, bulkArtifact.Type, bulkArtifact.Id); err != nil {
+				results[bulkArtifact] = fmt.Sprintf("FAILED: %s", err)
+				failed++
+				continue
+			}
+			results[bulkArtifact] = "TRIGGERED"
+			triggered = append(triggered, bulkArtifact)
+		}
+
+		if !*deployWait {
+			continue
+		}
+		if len(triggered) > 0 {
+			log.Printf("Waiting for start of %d artifacts...", len(triggered))
+		}
+		for _, bulkArtifact := range triggered {
+			if err := waitForBulkDeployment(client, bulkArtifact); err != nil {
+				results[bulkArtifact] = fmt.Sprintf("FAILED: %s", err)
+				failed++
+				continue
+			}
+			results[bulkArtifact] = "STARTED"
+		}
+	}
+
+	printBulkResults(selected, results)
+	fmt.Printf("\nDeployed %d artifacts: %d succeeded, %d failed\n", len(selected), len(selected)-failed, failed)
+	if failed > 0 {
+		log.Fatalf("Deployment of %d artifacts failed", failed)
+	}
+}
+
+//Artifact matches state filters. Artifacts in any state match, if no state filter is set
+func matchesDeployState(bulkArtifact *bulkArtifact) bool {
+	if !*deployOnlyChanged && !*deployOnlyNotDeployed && !*deployErrors {
+		return true
+	}
+
+	deployed := bulkArtifact.Runtime != nil
+	if *deployOnlyNotDeployed && !deployed {
+		return true
+	}
+	if *deployOnlyChanged && (!deployed || (!isDraftVersion(bulkArtifact.Version) && bulkArtifact.Runtime.Version != bulkArtifact.Version)) {
+		return true
+	}
+	return *deployErrors && deployed && bulkArtifact.Runtime.Status == "ERROR"
+}
+
+//Version of artifact in draft state can not be compared with deployed one
+func isDraftVersion(version string) bool {
+	return strings.EqualFold(version, "active")
+}
+
+func waitForBulkDeployment(client *cpiclient.CPIClient, bulkArtifact *bulkArtifact) error {
+	version := bulkArtifact.Version
+	if isDraftVersion(version) {
+		version = ""
+	}
+	return client.WaitForIntegrationRuntimeArtifactDeployment(bulkArtifact.Id, version, bulkArtifact.Runtime, *deployTimeout)
+}
+
+//Integration flows and value mappings of package from --pkg, or of all packages of environment, which match filter
+func getBulkArtifacts(env *landscape.Environment, filter *bulkFilter) ([]*bulkArtifact, error) {
+	client := env.System.Client
+
+	artifactType := ""
+	switch strings.ToLower(*filter.artifactType) {
+	case "":
+	case "iflow", "integration-flow", strings.ToLower(artifactTypeIflow):
+		artifactType = artifactTypeIflow
+	case "value-mapping", "valuemapping", strings.ToLower(artifactTypeValueMapping):
+		artifactType = artifactTypeValueMapping
+	default:
+		return nil, fmt.Errorf("unknown artifact type %s, use iflow or value-mapping", *filter.artifactType)
+	}
+
+	var packageIds []string
+	if *pkg != "" {
+		packageIds = []string{*pkg}
+	} else {
+		integrationPackages, err := client.ReadIntegrationPackages()
+		if err != nil {
+			return nil, err
+		}
+		for _, integrationPackage := range integrationPackages {
+			if globalLandscape.GetPackageEnvironment(env.System, integrationPackage.Id) == env {
+				packageIds = append(packageIds, integrationPackage.Id)
+			}
+		}
+	}
+
+	runtimeArtifacts, err := client.ReadIntegrationRuntimeArtifacts()
+	if err != nil {
+		return nil, err
+	}
+	runtimeArtifactsById := make(map[string]*cpiclient.IntegrationRuntimeArtifact)
+	for _, runtimeArtifact := range runtimeArtifacts {
+		runtimeArtifactsById[runtimeArtifact.Id] = runtimeArtifact
+	}
+
+	var artifacts []*bulkArtifact
+	for _, packageId := range packageIds {
+		if artifactType != artifactTypeIflow {
+			valueMappings, err := client.ReadValueMappingDesigntimeArtifacts(packageId)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read value mappings of package %s: %s", packageId, err)
+			}
+			for _, valueMapping := range valueMappings {
+				artifacts = append(artifacts, &bulkArtifact{Id: valueMapping.Id, Type: artifactTypeValueMapping, PackageId: packageId, Version: valueMapping.Version})
+			}
+		}
+		if artifactType != artifactTypeValueMapping {
+			designtimeArtifacts, err := client.ReadIntegrationDesigntimeArtifacts(packageId, false)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read artifacts of package %s: %s", packageId, err)
+			}
+			for _, designtimeArtifact := range designtimeArtifacts {
+				artifacts = append(artifacts, &bulkArtifact{Id: designtimeArtifact.Id, Type: artifactTypeIflow, PackageId: packageId, Version: designtimeArtifact.Version})
+			}
+		}
+	}
+
+	var filtered []*bulkArtifact
+	for _, bulkArtifact := range artifacts {
+		matched, err := matchesAnyPattern(bulkArtifact.Id, *filter.patterns)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			bulkArtifact.Runtime = runtimeArtifactsById[bulkArtifact.Id]
+			filtered = append(filtered, bulkArtifact)
+		}
+	}
+	return filtered, nil
+}
+
+func printBulkResults(artifacts []*bulkArtifact, results map[*bulkArtifact]string) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "#\tType\tArtifactId\tPackage\tVersion\tPrevious status\tResult")
+	for index, bulkArtifact := range artifacts {
+		previous := "Not deployed"
+		if bulkArtifact.Runtime != nil {
+			previous = fmt.Sprintf("%s %s", bulkArtifact.Runtime.Status, bulkArtifact.Runtime.Version)
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", index+1, bulkArtifact.Type, bulkArtifact.Id, bulkArtifact.PackageId, bulkArtifact.Version, previous, results[bulkArtifact])
+	}
+	writer.Flush()
+}
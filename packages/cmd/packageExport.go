@@ -0,0 +1,304 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/iflow"
+	"github.com/Trifolium-project/landscaper/packages/landscape"
+	"github.com/Trifolium-project/landscaper/packages/util"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+//Layout of exported package directory:
+//package.yaml, iflows/<Id>/artifact.yaml, iflows/<Id>/configuration.yaml, iflows/<Id>/content/...,
+//valuemappings/<Id>/artifact.yaml, valuemappings/<Id>/content/...
+//IDs and names are stored as in original environment
+const (
+	exportPackageFile       = "package.yaml"
+	exportIflowDir          = "iflows"
+	exportValueMappingDir   = "valuemappings"
+	exportMetadataFile      = "artifact.yaml"
+	exportConfigurationFile = "configuration.yaml"
+	exportContentDir        = "content"
+)
+
+//Artifact types, same as type of runtime artifact
+const (
+	artifactTypeIflow        = "INTEGRATION_FLOW"
+	artifactTypeValueMapping = "VALUE_MAPPING"
+)
+
+//Metadata of exported artifact, which is stored next to its unpacked content
+type ExportedArtifactYAML struct {
+	Id          string `yaml:"id"`
+	Name        string `yaml:"name"`
+	Description string `yaml:"description,omitempty"`
+	Version     string `yaml:"version"`
+}
+
+var exportDir *string
+
+// packageExportCmd represents the export command
+var packageExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export package to local directory",
+	Long: `Export package metadata, integration flows and value mappings of --env environment to local directory.
+Every artifact is unpacked into its own folder together with its configuration, so directory can be versioned in git.
+IDs and names are stored as in original environment. Values of parameters are stored in configuration.yaml, parameters.prop of content keeps only keys.
+Values of parameters, which are declared as secrets in landscape file, are not exported.
+Folders of artifacts, which no longer exist in package, are removed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		packageExport()
+	},
+}
+
+func init() {
+	packageCmd.AddCommand(packageExportCmd)
+
+	exportDir = packageExportCmd.Flags().String("dir", "", "Target directory")
+
+	packageExportCmd.MarkFlagRequired("dir")
+}
+
+func packageExport() {
+	if globalLandscape == nil {
+		println("Global landscape is not instantiated")
+		return
+	}
+
+	currentEnvironment, err := globalLandscape.GetEnvironment(*environment)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	if *pkg == "" {
+		log.Fatalln("Package is not provided, please use --pkg flag")
+	}
+
+	exported, err := exportPackage(currentEnvironment, *pkg, *exportDir)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "#\tType\tArtifactId\tVersion\tFolder")
+	for index, exportedArtifact := range exported {
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\n", index+1, exportedArtifact.Type, exportedArtifact.Id, exportedArtifact.Version, exportedArtifact.Dir)
+	}
+	writer.Flush()
+}
+
+//Artifact in exported package directory
+type exportedArtifact struct {
+	ExportedArtifactYAML
+	//Artifact type: INTEGRATION_FLOW or VALUE_MAPPING
+	Type string
+	Dir  string
+	//Exported configuration, only integration flows have it
+	Parameters []ConfigurationParameterYAML
+}
+
+//Write package of environment into directory. Returns list of exported artifacts
+func exportPackage(env *landscape.Environment, packageId string, dir string) ([]*exportedArtifact, error) {
+	client := env.System.Client
+
+	integrationPackage, err := client.ReadIntegrationPackage(packageId)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read package %s: %s", packageId, err)
+	}
+	designtimeArtifacts, err := client.ReadIntegrationDesigntimeArtifacts(packageId, true)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read artifacts of package %s: %s", packageId, err)
+	}
+	valueMappings, err := client.ReadValueMappingDesigntimeArtifacts(packageId)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read value mappings of package %s: %s", packageId, err)
+	}
+
+	descriptor := newExportedPackageDescriptor(env, integrationPackage)
+
+	//Artifact folders are written from scratch, so removed artifacts disappear from directory
+	for _, folder := range []string{exportIflowDir, exportValueMappingDir} {
+		if err := os.RemoveAll(filepath.Join(dir, folder)); err != nil {
+			return nil, err
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := writeYAMLFile(filepath.Join(dir, exportPackageFile), descriptor); err != nil {
+		return nil, err
+	}
+
+	var exported []*exportedArtifact
+
+	for _, designtimeArtifact := range designtimeArtifacts {
+		downloadedArtifact, err := client.DownloadIntegrationDesigntimeArtifact(designtimeArtifact.Id, designtimeArtifact.Version)
+		if err != nil {
+			return nil, fmt.Errorf("unable to download %s: %s", designtimeArtifact.Id, err)
+		}
+
+		baseArtifactId := env.BaseArtifactId(designtimeArtifact.Id)
+		exportedIflow := &exportedArtifact{
+			ExportedArtifactYAML: ExportedArtifactYAML{
+				Id:          baseArtifactId,
+				Name:        baseArtifactName(env, designtimeArtifact.Name),
+				Description: designtimeArtifact.Description,
+				Version:     designtimeArtifact.Version,
+			},
+			Type: artifactTypeIflow,
+			Dir:  filepath.Join(dir, exportIflowDir, baseArtifactId),
+		}
+
+		//Secret values are not written to disk
+		var secretKeys map[string]bool
+		if _, ok := globalLandscape.Packages[descriptor.Id]; ok {
+			secretKeys = getSecretKeys(env, designtimeArtifact.Id, packageId)
+		}
+		for _, configuration := range designtimeArtifact.Configurations {
+			if secretKeys[configuration.ParameterKey] {
+				continue
+			}
+			exportedIflow.Parameters = append(exportedIflow.Parameters, ConfigurationParameterYAML{
+				Key:   configuration.ParameterKey,
+				Value: configuration.ParameterValue,
+				Type:  configuration.DataType,
+			})
+		}
+
+		if err := writeExportedArtifact(exportedIflow, downloadedArtifact.ArtifactContent); err != nil {
+			return nil, err
+		}
+		exported = append(exported, exportedIflow)
+	}
+
+	for _, valueMapping := range valueMappings {
+		downloadedValueMapping, err := client.DownloadValueMappingDesigntimeArtifact(valueMapping.Id, valueMapping.Version)
+		if err != nil {
+			return nil, fmt.Errorf("unable to download %s: %s", valueMapping.Id, err)
+		}
+
+		baseArtifactId := env.BaseArtifactId(valueMapping.Id)
+		exportedValueMapping := &exportedArtifact{
+			ExportedArtifactYAML: ExportedArtifactYAML{
+				Id:          baseArtifactId,
+				Name:        baseArtifactName(env, valueMapping.Name),
+				Description: valueMapping.Description,
+				Version:     valueMapping.Version,
+			},
+			Type: artifactTypeValueMapping,
+			Dir:  filepath.Join(dir, exportValueMappingDir, baseArtifactId),
+		}
+
+		if err := writeExportedArtifact(exportedValueMapping, downloadedValueMapping.ArtifactContent); err != nil {
+			return nil, err
+		}
+		exported = append(exported, exportedValueMapping)
+	}
+
+	return exported, nil
+}
+
+//Package metadata with ID, name and short text of original environment
+func newExportedPackageDescriptor(env *landscape.Environment, integrationPackage *cpiclient.IntegrationPackage) *PackageDescriptor {
+	descriptor := &PackageDescriptor{
+		Id:             env.BasePackageId(integrationPackage.Id),
+		Name:           integrationPackage.Name,
+		ShortText:      integrationPackage.ShortText,
+		Description:    integrationPackage.Description,
+		Vendor:         integrationPackage.Vendor,
+		Version:        integrationPackage.Version,
+		Keywords:       splitList(integrationPackage.Keywords),
+		Products:       splitList(integrationPackage.Products),
+		Countries:      splitList(integrationPackage.Countries),
+		Industries:     splitList(integrationPackage.Industries),
+		LineOfBusiness: splitList(integrationPackage.LineOfBusiness),
+	}
+
+	if env != globalLandscape.OriginalEnvironment {
+		descriptor.Name = env.BasePackageName(integrationPackage.Name)
+		descriptor.ShortText = env.BasePackageShortText(integrationPackage.ShortText)
+	}
+
+	return descriptor
+}
+
+func baseArtifactName(env *landscape.Environment, name string) string {
+	if env == globalLandscape.OriginalEnvironment {
+		return name
+	}
+	return env.BaseArtifactName(name)
+}
+
+//Write metadata, configuration and unpacked content of artifact into its folder
+func writeExportedArtifact(exported *exportedArtifact, artifactContent string) error {
+	content, err := base64.StdEncoding.DecodeString(artifactContent)
+	if err != nil {
+		return fmt.Errorf("unable to decode content of %s: %s", exported.Id, err)
+	}
+
+	//Values of parameters are stored in configuration.yaml, where secrets are filtered out
+	if exported.Type == artifactTypeIflow {
+		content, err = iflow.ClearParameterValues(content)
+		if err != nil {
+			return fmt.Errorf("unable to clear parameters of %s: %s", exported.Id, err)
+		}
+	}
+
+	err = util.UnzipToDirectory(content, filepath.Join(exported.Dir, exportContentDir))
+	if err != nil {
+		return fmt.Errorf("unable to unpack %s: %s", exported.Id, err)
+	}
+
+	err = writeYAMLFile(filepath.Join(exported.Dir, exportMetadataFile), exported.ExportedArtifactYAML)
+	if err != nil {
+		return err
+	}
+
+	if exported.Type != artifactTypeIflow {
+		return nil
+	}
+	return writeYAMLFile(filepath.Join(exported.Dir, exportConfigurationFile), ConfigurationFileYAML{Parameters: exported.Parameters})
+}
+
+func writeYAMLFile(fileName string, value interface{}) error {
+	content, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(fileName, content, 0644)
+}
+
+//Split comma separated list of package attribute
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,403 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/iflow"
+	"github.com/Trifolium-project/landscaper/packages/landscape"
+	"github.com/Trifolium-project/landscaper/packages/util"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var importDir *string
+var toDeployImported *bool
+
+// packageImportCmd represents the import command
+var packageImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import package from local directory",
+	Long: `Create or update package in --env environment from directory, written by package export.
+Package and artifact IDs and names follow naming convention of environment. Value mappings are imported before integration flows.
+Exported configuration is applied first, then configuration of environment from landscape file.
+Only parameters, which differ from artifact, are updated.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		packageImport()
+	},
+}
+
+func init() {
+	packageCmd.AddCommand(packageImportCmd)
+
+	importDir = packageImportCmd.Flags().String("dir", "", "Directory with exported package")
+	toDeployImported = packageImportCmd.Flags().BoolP("deploy", "d", false, "Indicate whether necessary to deploy imported artifacts")
+
+	packageImportCmd.MarkFlagRequired("dir")
+}
+
+func packageImport() {
+	if globalLandscape == nil {
+		println("Global landscape is not instantiated")
+		return
+	}
+
+	currentEnvironment, err := globalLandscape.GetEnvironment(*environment)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	results, err := importPackage(currentEnvironment, *importDir, func(*exportedArtifact) bool {
+		return *toDeployImported
+	})
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	printImportResults(results)
+}
+
+//Imported package or artifact
+type importResult struct {
+	Type      string
+	Id        string
+	PackageId string
+	Result    string
+	Deployed  bool
+}
+
+//Create or update package and its artifacts from exported directory. Artifacts, selected by toDeploy, are deployed
+func importPackage(env *landscape.Environment, dir string, toDeploy func(*exportedArtifact) bool) ([]*importResult, error) {
+	descriptor, exported, err := readExportedPackage(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	integrationPackage, created, err := createOrUpdatePackage(env, descriptor)
+	if err != nil {
+		return nil, err
+	}
+	result := "updated"
+	if created {
+		result = "created"
+	}
+	results := []*importResult{{Type: "PACKAGE", Id: integrationPackage.Id, PackageId: integrationPackage.Id, Result: result}}
+
+	//Value mappings are imported and deployed first, integration flows can depend on them
+	for _, exportedArtifact := range exported {
+		var id string
+		var updated bool
+		if exportedArtifact.Type == artifactTypeValueMapping {
+			id, updated, err = importValueMapping(env, integrationPackage.Id, exportedArtifact)
+		} else {
+			id, updated, err = importIflow(env, descriptor.Id, integrationPackage.Id, exportedArtifact)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		deployed := toDeploy(exportedArtifact)
+		if deployed {
+			err = deployImportedArtifact(env.System.Client, exportedArtifact.Type, id)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		result := "created"
+		if updated {
+			result = "updated"
+		}
+		results = append(results, &importResult{Type: exportedArtifact.Type, Id: id, PackageId: integrationPackage.Id, Result: result, Deployed: deployed})
+	}
+
+	return results, nil
+}
+
+func printImportResults(results []*importResult) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "#\tType\tArtifactId\tPackage\tResult\tDeployed")
+	for index, result := range results {
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%t\n", index+1, result.Type, result.Id, result.PackageId, result.Result, result.Deployed)
+	}
+	writer.Flush()
+}
+
+//Read package descriptor and artifacts from exported directory. Value mappings are returned first
+func readExportedPackage(dir string) (*PackageDescriptor, []*exportedArtifact, error) {
+	descriptor, err := readPackageDescriptor(filepath.Join(dir, exportPackageFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	if descriptor.Id == "" {
+		return nil, nil, fmt.Errorf("package ID is not found in %s", filepath.Join(dir, exportPackageFile))
+	}
+	if descriptor.Name == "" {
+		descriptor.Name = descriptor.Id
+	}
+
+	valueMappings, err := readExportedArtifacts(filepath.Join(dir, exportValueMappingDir), artifactTypeValueMapping)
+	if err != nil {
+		return nil, nil, err
+	}
+	iflows, err := readExportedArtifacts(filepath.Join(dir, exportIflowDir), artifactTypeIflow)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return descriptor, append(valueMappings, iflows...), nil
+}
+
+//Read artifact folders in directory. Missing directory means, that there are no artifacts of this type
+func readExportedArtifacts(dir string, artifactType string) ([]*exportedArtifact, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var artifacts []*exportedArtifact
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		artifactDir := filepath.Join(dir, entry.Name())
+
+		exported := &exportedArtifact{Type: artifactType, Dir: artifactDir}
+		err := readYAMLFile(filepath.Join(artifactDir, exportMetadataFile), &exported.ExportedArtifactYAML)
+		if err != nil {
+			return nil, err
+		}
+		if exported.Id == "" {
+			exported.Id = entry.Name()
+		}
+		if exported.Name == "" {
+			exported.Name = exported.Id
+		}
+
+		configurationFile := filepath.Join(artifactDir, exportConfigurationFile)
+		if _, err := os.Stat(configurationFile); err == nil {
+			configuration := ConfigurationFileYAML{}
+			if err := readYAMLFile(configurationFile, &configuration); err != nil {
+				return nil, err
+			}
+			exported.Parameters = configuration.Parameters
+		}
+
+		artifacts = append(artifacts, exported)
+	}
+
+	sort.Slice(artifacts, func(i, j int) bool {
+		return artifacts[i].Id < artifacts[j].Id
+	})
+
+	return artifacts, nil
+}
+
+func readYAMLFile(fileName string, value interface{}) error {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return err
+	}
+	if err := yaml.Unmarshal(content, value); err != nil {
+		return fmt.Errorf("unable to parse %s: %s", fileName, err)
+	}
+	return nil
+}
+
+//Pack unpacked artifact content. Hidden files, e.g. .project, are part of artifact
+func (exported *exportedArtifact) content() ([]byte, error) {
+	return util.ZipDirectoryAll(filepath.Join(exported.Dir, exportContentDir))
+}
+
+//Name of artifact copy in environment
+func (exported *exportedArtifact) name(env *landscape.Environment) string {
+	if env == globalLandscape.OriginalEnvironment {
+		return exported.Name
+	}
+	return env.ArtifactName(exported.Name)
+}
+
+//Create or update integration flow and apply configuration. Returns ID of artifact in environment
+func importIflow(env *landscape.Environment, basePackageId string, packageId string, exported *exportedArtifact) (string, bool, error) {
+	id, updated, err := uploadExportedIflow(env, packageId, exported)
+	if err != nil {
+		return "", false, err
+	}
+
+	configurations, err := getImportConfigurations(env, basePackageId, exported)
+	if err != nil {
+		return "", false, fmt.Errorf("unable to configure %s: %s", id, err)
+	}
+	err = applyImportConfigurations(env.System.Client, id, configurations)
+	if err != nil {
+		return "", false, fmt.Errorf("unable to configure %s: %s", id, err)
+	}
+
+	return id, updated, nil
+}
+
+//Create or update content of integration flow. Returns ID of artifact in environment
+func uploadExportedIflow(env *landscape.Environment, packageId string, exported *exportedArtifact) (string, bool, error) {
+	content, err := exported.content()
+	if err != nil {
+		return "", false, fmt.Errorf("unable to pack %s: %s", exported.Id, err)
+	}
+
+	newArtifact := &cpiclient.IntegrationDesigntimeArtifact{
+		Id:              env.ArtifactId(exported.Id),
+		Name:            exported.name(env),
+		PackageId:       packageId,
+		Description:     exported.Description,
+		ArtifactContent: base64.StdEncoding.EncodeToString(content),
+	}
+
+	updated, err := createOrUpdateArtifact(env.System.Client, newArtifact)
+	if err != nil {
+		return "", false, fmt.Errorf("unable to import %s: %s", newArtifact.Id, err)
+	}
+
+	return newArtifact.Id, updated, nil
+}
+
+//Value mappings cannot be updated, existing value mapping is replaced
+func importValueMapping(env *landscape.Environment, packageId string, exported *exportedArtifact) (string, bool, error) {
+	client := env.System.Client
+	id := env.ArtifactId(exported.Id)
+
+	content, err := exported.content()
+	if err != nil {
+		return "", false, fmt.Errorf("unable to pack %s: %s", exported.Id, err)
+	}
+
+	existing, err := client.ReadValueMappingDesigntimeArtifacts(packageId)
+	if err != nil && !cpiclient.IsNotFound(err) {
+		return "", false, err
+	}
+	updated := false
+	for _, valueMapping := range existing {
+		if valueMapping.Id != id {
+			continue
+		}
+		if err := client.DeleteValueMappingDesigntimeArtifact(id, valueMapping.Version); err != nil {
+			return "", false, fmt.Errorf("unable to replace %s: %s", id, err)
+		}
+		updated = true
+	}
+
+	err = client.UploadValueMappingDesigntimeArtifact(&cpiclient.ValueMappingDesigntimeArtifact{
+		Id:              id,
+		Name:            exported.name(env),
+		PackageId:       packageId,
+		ArtifactContent: base64.StdEncoding.EncodeToString(content),
+	})
+	if err != nil {
+		return "", false, fmt.Errorf("unable to import %s: %s", id, err)
+	}
+
+	return id, updated, nil
+}
+
+//Exported configuration, overridden with configuration of environment from landscape file
+func getImportConfigurations(env *landscape.Environment, basePackageId string, exported *exportedArtifact) ([]*cpiclient.Configuration, error) {
+	var configurations []*cpiclient.Configuration
+	for _, parameter := range exported.Parameters {
+		configurations = append(configurations, &cpiclient.Configuration{
+			ParameterKey:   parameter.Key,
+			ParameterValue: parameter.Value,
+			DataType:       parameter.Type,
+		})
+	}
+
+	if _, ok := globalLandscape.Packages[basePackageId]; !ok {
+		return configurations, nil
+	}
+
+	parameters, _ := globalLandscape.GetArtifactConfiguration(env.Id, basePackageId, exported.Id)
+	parameters, err := landscape.ResolveParameters(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, parameter := range parameters {
+		conf, err := getConfiguration(parameter.Key, configurations)
+		if err != nil {
+			conf = &cpiclient.Configuration{ParameterKey: parameter.Key}
+			configurations = append(configurations, conf)
+		}
+		conf.ParameterValue = parameter.Value
+		conf.Sensitive = parameter.Sensitive
+	}
+
+	return configurations, nil
+}
+
+//Update parameters, which differ from artifact. Data type is taken from artifact, keys, which are not found in artifact, are skipped
+func applyImportConfigurations(client *cpiclient.CPIClient, artifactId string, configurations []*cpiclient.Configuration) error {
+	if len(configurations) == 0 {
+		return nil
+	}
+
+	tenantConfigurations, err := client.ReadIntegrationDesigntimeArtifactConfigurations(artifactId, "active")
+	if err != nil {
+		return err
+	}
+
+	for _, conf := range configurations {
+		tenantConf, err := getConfiguration(conf.ParameterKey, tenantConfigurations)
+		if err != nil {
+			log.Printf("Parameter %s is not found in %s and is skipped", conf.ParameterKey, artifactId)
+			continue
+		}
+		if tenantConf.ParameterValue == conf.ParameterValue {
+			continue
+		}
+		if !conf.Sensitive {
+			if err := iflow.ValidateParameterValue(conf.ParameterValue, tenantConf.DataType); err != nil {
+				return fmt.Errorf("parameter %s: %s", conf.ParameterKey, err)
+			}
+		}
+
+		err = client.UpdateIntegrationDesigntimeArtifactConfiguration(artifactId, "active", &cpiclient.Configuration{
+			ParameterKey:   conf.ParameterKey,
+			ParameterValue: conf.ParameterValue,
+			DataType:       tenantConf.DataType,
+			Sensitive:      conf.Sensitive,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func deployImportedArtifact(client *cpiclient.CPIClient, artifactType string, id string) error {
+	if artifactType == artifactTypeValueMapping {
+		return client.DeployValueMappingDesigntimeArtifact(id, "active")
+	}
+	return client.DeployIntegrationDesigntimeArtifact(id, "active")
+}
@@ -16,6 +16,7 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/base64"
 	"fmt"
 	"log"
 	"os"
@@ -23,6 +24,8 @@ import (
 	"text/tabwriter"
 
 	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/iflow"
+	"github.com/Trifolium-project/landscaper/packages/landscape"
 	"github.com/Trifolium-project/landscaper/packages/util"
 	"github.com/spf13/cobra"
 )
@@ -30,6 +33,8 @@ import (
 var targetEnv *string
 var iflowList *[]string
 var toDeploy *bool
+var moveLint *bool
+var offlineConfig *bool
 
 // moveCmd represents the move command
 var packageMoveCmd = &cobra.Command{
@@ -82,6 +87,8 @@ func init() {
 	targetEnv = packageMoveCmd.Flags().String("target-env", "", "Target environment")
 	toDeploy = packageMoveCmd.Flags().BoolP("deploy", "d", false, "Indicate whether necessary to deploy changed artifacts in target environment")
 	iflowList = packageMoveCmd.Flags().StringSliceP("iflow", "f", []string{}, "List of integration flows to")
+	offlineConfig = packageMoveCmd.Flags().Bool("offline-config", false, "Write configuration of target environment into parameters.prop of artifact before upload, instead of updating parameters after upload")
+	moveLint = packageMoveCmd.Flags().Bool("lint", false, "Check integration flows with lint rules before transport, transport is cancelled if lint fails")
 	// Cobra supports Persistent Flags which will work for this command
 	// and all subcommands, e.g.:
 	// moveCmd.PersistentFlags().String("foo", "", "A help for foo")
@@ -120,7 +127,8 @@ func packageMove() {
 		log.Fatalln(err)
 	}
 
-	targetPackageId := *pkg + targetEnvironment.Suffix
+	basePackageId := originalEnvironment.BasePackageId(*pkg)
+	targetPackageId := targetEnvironment.PackageId(basePackageId)
 
 	sourcePackage, err := originalEnvironment.System.Client.ReadIntegrationPackage(*pkg)
 	if err != nil {
@@ -160,6 +168,16 @@ func packageMove() {
 		log.Fatalf("These artifacts in package %s are in Draft state: %s. Please save them as version.", *pkg, draftIFlows)
 	}
 
+	//Pre-transport gate: versions, which are transported, are checked with lint rules
+	if *moveLint {
+		config := getLintConfig("")
+		results := lintDesigntimeArtifacts(originalEnvironment, sourceArtifacts, config)
+		printLintResults(results)
+		if lintFailed(results, config) {
+			log.Fatalf("Transport is cancelled: findings with severity %s or higher are found\n", config.FailOn)
+		}
+	}
+
 	//Transport package
 	tagretPackage, err := targetEnvironment.System.Client.ReadIntegrationPackage(targetPackageId)
 	if err != nil {
@@ -172,9 +190,9 @@ func packageMove() {
 
 		tagretPackage := &cpiclient.IntegrationPackage{
 			Id:          targetPackageId,
-			Name:        targetEnvironment.Suffix + " " + sourcePackage.Name,
+			Name:        targetEnvironment.PackageName(sourcePackage.Name),
 			Description: sourcePackage.Description,
-			ShortText:   sourcePackage.ShortText + "(environment - '" + targetEnvironment.Id + "')",
+			ShortText:   targetEnvironment.PackageShortText(sourcePackage.ShortText),
 			Vendor:      sourcePackage.Vendor,
 			Version:     sourcePackage.Version,
 
@@ -203,7 +221,8 @@ func packageMove() {
 
 	//Transport artifacts
 	for index, sourceArtifact := range sourceArtifacts {
-		id := sourceArtifact.Id + targetEnvironment.Suffix
+		baseArtifactId := originalEnvironment.BaseArtifactId(sourceArtifact.Id)
+		id := targetEnvironment.ArtifactId(baseArtifactId)
 
 		transportArtifact := false
 		artifactExistsInTarget := false
@@ -218,50 +237,53 @@ func packageMove() {
 
 		if transportArtifact {
 
-			parameters, err := globalLandscape.GetArtifactConfiguration(*targetEnv, sourceArtifact.PackageId, sourceArtifact.Id)
-
-			id := sourceArtifact.Id + targetEnvironment.Suffix
+			parameters, err := globalLandscape.GetArtifactConfiguration(*targetEnv, basePackageId, baseArtifactId)
 
-			if artifactExistsInTarget {
-				version := currentTargetArtifactVersions[id]
+			//Secret references are resolved before artifact is replaced in target environment
+			parameters, err = landscape.ResolveParameters(parameters)
+			if err != nil {
+				log.Fatalf("Unable to resolve configuration of %s: %s", id, err)
+			}
 
-				targetEnvironment.System.Client.DeleteIntegrationDesigntimeArtifact(id, version)
-				if err != nil {
-					log.Fatalln(err)
-				}
+			//Configuration is checked before artifact is replaced in target environment
+			configurations, err := getTransportConfigurations(sourceArtifact, parameters)
+			if err != nil {
+				log.Fatalf("Transport of %s is cancelled: %s", id, err)
 			}
 
 			newArtifact, err := originalEnvironment.System.Client.DownloadIntegrationDesigntimeArtifact(sourceArtifact.Id, sourceArtifact.Version)
 			if err != nil {
 				log.Fatalln(err)
 			}
-			newArtifact.Name = sourceArtifact.Name + " " + targetEnvironment.Suffix
+			newArtifact.Name = targetEnvironment.ArtifactName(sourceArtifact.Name)
 			newArtifact.PackageId = targetPackageId
 			newArtifact.Id = id
 			newArtifact.Description = sourceArtifact.Description
 			newArtifact.Version = sourceArtifact.Version
 
+			//Artifact is uploaded already configured for target environment
+			if *offlineConfig {
+				newArtifact.ArtifactContent, configurations, err = applyOfflineConfiguration(newArtifact.ArtifactContent, configurations)
+				if err != nil {
+					log.Fatalf("Transport of %s is cancelled: %s", id, err)
+				}
+			}
+
+			if artifactExistsInTarget {
+				version := currentTargetArtifactVersions[id]
+
+				err = targetEnvironment.System.Client.DeleteIntegrationDesigntimeArtifact(id, version)
+				if err != nil {
+					log.Fatalln(err)
+				}
+			}
+
 			err = targetEnvironment.System.Client.UploadIntegrationDesigntimeArtifact(newArtifact)
 			if err != nil {
 				log.Fatalln(err)
 			}
 
-			for _, parameter := range parameters {
-				sourceConf, err := sourceArtifact.GetConfiguration(parameter.Key)
-				var conf  *cpiclient.Configuration
-				if err != nil {
-					conf = &cpiclient.Configuration{
-						ParameterKey:   parameter.Key,
-						ParameterValue: parameter.Value,
-						DataType:       sourceConf.DataType,
-					}
-				} else {
-					conf = &cpiclient.Configuration{
-						ParameterKey:   parameter.Key,
-						ParameterValue: parameter.Value,
-						DataType:       parameter.Type,
-					}
-				}
+			for _, conf := range configurations {
 				err = targetEnvironment.System.Client.UpdateIntegrationDesigntimeArtifactConfiguration(newArtifact.Id, newArtifact.Version, conf)
 				if err != nil {
 					log.Fatalln(err)
@@ -286,4 +308,65 @@ func packageMove() {
 	writer.Flush()
 }
 
+//Configuration of transported artifact. Data type is taken from artifact, keys, which are not found in artifact, are skipped
+func getTransportConfigurations(sourceArtifact *cpiclient.IntegrationDesigntimeArtifact, parameters []*landscape.Parameter) ([]*cpiclient.Configuration, error) {
+	var configurations []*cpiclient.Configuration
+	for _, parameter := range parameters {
+		sourceConf, err := sourceArtifact.GetConfiguration(parameter.Key)
+		if err != nil {
+			//Package defaults are applied only to artifacts, which have such parameter
+			if !parameter.Inherited {
+				log.Printf("Parameter %s is not found in %s version %s and is skipped, use config audit to find unknown keys", parameter.Key, sourceArtifact.Id, sourceArtifact.Version)
+			}
+			continue
+		}
+
+		if !parameter.Sensitive {
+			if err := iflow.ValidateParameterValue(parameter.Value, sourceConf.DataType); err != nil {
+				return nil, fmt.Errorf("parameter %s: %s", parameter.Key, err)
+			}
+		}
+
+		configurations = append(configurations, &cpiclient.Configuration{
+			ParameterKey:   parameter.Key,
+			ParameterValue: parameter.Value,
+			DataType:       sourceConf.DataType,
+			Sensitive:      parameter.Sensitive,
+		})
+	}
+	return configurations, nil
+}
+
+//Write configuration into parameters.prop of base64 encoded artifact. Values are validated against parameters.propdef.
+//Sensitive values are not stored in artifact content, they are returned to be updated after upload
+func applyOfflineConfiguration(content string, configurations []*cpiclient.Configuration) (string, []*cpiclient.Configuration, error) {
+	decoded, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to decode artifact content: %s", err)
+	}
+	artifactContent, err := iflow.Parse(decoded)
+	if err != nil {
+		return "", nil, err
+	}
+
+	values := make(map[string]string)
+	var remaining []*cpiclient.Configuration
+	for _, conf := range configurations {
+		if conf.Sensitive {
+			remaining = append(remaining, conf)
+			continue
+		}
+		if err := artifactContent.ValidateParameterValue(conf.ParameterKey, conf.ParameterValue); err != nil {
+			return "", nil, fmt.Errorf("parameter %s: %s", conf.ParameterKey, err)
+		}
+		values[conf.ParameterKey] = conf.ParameterValue
+	}
+
+	updated, err := iflow.SetParameterValues(decoded, values)
+	if err != nil {
+		return "", nil, err
+	}
+	return base64.StdEncoding.EncodeToString(updated), remaining, nil
+}
+
 //TODO: Download backup package and iflows before making change
@@ -0,0 +1,111 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var undeployFilter *bulkFilter
+var undeployAllPackages *bool
+var undeployWait *bool
+var undeployPackageTimeout *time.Duration
+
+// packageUndeployCmd represents the package undeploy command
+var packageUndeployCmd = &cobra.Command{
+	Use:   "undeploy",
+	Short: "Undeploy artifacts of package or environment",
+	Long: `Undeploy deployed integration flows and value mappings of package from --pkg, or of all packages of --env environment with --all.
+Integration flows are undeployed before value mappings, which they can depend on.
+Artifacts can be selected by glob pattern and type. Removal of every artifact from runtime is awaited, and summary is printed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		packageUndeploy()
+	},
+}
+
+func init() {
+	packageCmd.AddCommand(packageUndeployCmd)
+
+	undeployFilter = addBulkFilterFlags(packageUndeployCmd)
+	undeployAllPackages = packageUndeployCmd.Flags().Bool("all", false, "Undeploy artifacts of all packages of environment, if --pkg is not set")
+	undeployWait = packageUndeployCmd.Flags().Bool("wait", true, "Wait until artifacts are removed from runtime")
+	undeployPackageTimeout = packageUndeployCmd.Flags().Duration("timeout", 5*time.Minute, "Maximum time to wait for undeploy of every artifact")
+}
+
+func packageUndeploy() {
+	if globalLandscape == nil {
+		println("Global landscape is not instantiated")
+		return
+	}
+
+	if *pkg == "" && !*undeployAllPackages {
+		log.Fatalln("Package is not provided, please use --pkg flag, or --all to undeploy all packages of environment")
+	}
+
+	currentEnvironment, err := globalLandscape.GetEnvironment(*environment)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	artifacts, err := getBulkArtifacts(currentEnvironment, undeployFilter)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	var selected []*bulkArtifact
+	for _, bulkArtifact := range artifacts {
+		if bulkArtifact.Runtime != nil {
+			selected = append(selected, bulkArtifact)
+		}
+	}
+	if len(selected) == 0 {
+		fmt.Println("No deployed artifacts to undeploy")
+		return
+	}
+
+	client := currentEnvironment.System.Client
+	results := make(map[*bulkArtifact]string)
+	failed := 0
+
+	//Integration flows are stopped before value mappings
+	for _, artifactType := range []string{artifactTypeIflow, artifactTypeValueMapping} {
+		for _, bulkArtifact := range selected {
+			if bulkArtifact.Type != artifactType {
+				continue
+			}
+			err := client.UndeployIntegrationRuntimeArtifact(bulkArtifact.Id)
+			if err == nil && *undeployWait {
+				err = client.WaitForIntegrationRuntimeArtifactRemoval(bulkArtifact.Id, *undeployPackageTimeout)
+			}
+			if err != nil {
+				results[bulkArtifact] = fmt.Sprintf("FAILED: %s", err)
+				failed++
+				continue
+			}
+			results[bulkArtifact] = "UNDEPLOYED"
+		}
+	}
+
+	printBulkResults(selected, results)
+	fmt.Printf("\nUndeployed %d artifacts: %d succeeded, %d failed\n", len(selected), len(selected)-failed, failed)
+	if failed > 0 {
+		log.Fatalf("Undeploy of %d artifacts failed", failed)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/util"
+	"github.com/spf13/cobra"
+)
+
+var restoreIn *string
+var restoreToDeploy *bool
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore packages from snapshot archive",
+	Long: `Create or update packages from snapshot archive in --env environment. Package and artifact IDs and names follow naming convention
+of environment, configuration of environment from landscape file is applied over saved configuration.
+Custom tags are restored, if they are defined in tenant, and artifacts, which were deployed, are deployed again.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		restore()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	restoreIn = restoreCmd.Flags().String("in", "", "Path to snapshot archive(tar.gz)")
+	restoreToDeploy = restoreCmd.Flags().Bool("deploy", true, "Deploy artifacts, which were deployed, when snapshot was taken")
+
+	restoreCmd.MarkFlagRequired("in")
+}
+
+func restore() {
+	if globalLandscape == nil {
+		println("Global landscape is not instantiated")
+		return
+	}
+
+	currentEnvironment, err := globalLandscape.GetEnvironment(*environment)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	dir, err := os.MkdirTemp("", "landscaper-restore")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := util.UntarGzToDirectory(*restoreIn, dir); err != nil {
+		log.Fatalf("Unable to unpack snapshot %s: %s", *restoreIn, err)
+	}
+
+	snapshotYAML := &SnapshotYAML{}
+	if err := readYAMLFile(filepath.Join(dir, snapshotFile), snapshotYAML); err != nil {
+		log.Fatalln(err)
+	}
+	fmt.Printf("Restoring snapshot of %s(%s), taken %s, to %s...\n", snapshotYAML.Environment, snapshotYAML.System, snapshotYAML.Created, currentEnvironment.Id)
+	for _, failed := range snapshotYAML.Failed {
+		log.Printf("Package %s was not saved in snapshot and is not restored: %s", failed.Id, failed.Error)
+	}
+
+	var results []*importResult
+	for _, basePackageId := range snapshotYAML.Packages {
+		packageDir := filepath.Join(dir, basePackageId)
+
+		state := &SnapshotPackageStateYAML{}
+		if err := readYAMLFile(filepath.Join(packageDir, snapshotStateFile), state); err != nil {
+			log.Fatalln(err)
+		}
+		deployed := make(map[string]bool)
+		for _, deployedArtifact := range state.Deployed {
+			deployed[deployedArtifact.Id] = true
+		}
+
+		packageResults, err := importPackage(currentEnvironment, packageDir, func(exported *exportedArtifact) bool {
+			return *restoreToDeploy && deployed[exported.Id]
+		})
+		if err != nil {
+			log.Fatalf("Unable to restore package %s: %s", basePackageId, err)
+		}
+		results = append(results, packageResults...)
+
+		//Tag definitions are tenant settings, values of undefined tags cannot be restored
+		packageId := currentEnvironment.PackageId(basePackageId)
+		for _, customTag := range state.CustomTags {
+			err := currentEnvironment.System.Client.UpdateIntegrationPackageCustomTag(packageId, &cpiclient.CustomTag{Name: customTag.Name, Value: customTag.Value})
+			if err != nil {
+				log.Printf("Unable to restore custom tag %s of %s: %s", customTag.Name, packageId, err)
+			}
+		}
+	}
+
+	printImportResults(results)
+}
@@ -80,21 +80,26 @@ func initConfig() {
 
 	_ = godotenv.Load()
 
-	//Set landscape configuration path
-	var landscapeFilePath string 
-	if *landscapeFile  != "" {
-		landscapeFilePath = *landscapeFile 
-	} else {
-		//Default landscape file location
-		landscapeFilePath = "conf/landscape.yaml"
+	//Systems are not created, so credentials are not requested
+	if isLandscapeFileCommand() {
+		initViper()
+		return
 	}
 
-	landscape, err := landscape.NewLandscape(landscapeFilePath)
+	landscape, err := landscape.NewLandscape(getLandscapeFilePath())
+	if os.IsNotExist(err) {
+		//Landscape file does not exist before it is generated with init command
+		if isInitCommand() {
+			initViper()
+			return
+		}
+		log.Fatalf("Landscape file %s is not found: %s", getLandscapeFilePath(), err)
+	}
 	if err != nil {
 		log.Println(err)	
 	} 
 	if landscape == nil {
-		log.Fatalln("Unable to read landscaper configuration")
+		log.Fatalf("Unable to read landscaper configuration %s", getLandscapeFilePath())
 	}
 	globalLandscape = landscape
 
@@ -108,14 +113,14 @@ func initConfig() {
 		log.Fatalln(err)	
 	}
 	
-	//Add environment suffix to package name
+	//Apply naming convention of environment to package ID
 	if(*pkg != ""){
-		*pkg = *pkg + env.Suffix
+		*pkg = env.PackageId(*pkg)
 	}
 
-	//Add environment suffix to artifact name	
+	//Apply naming convention of environment to artifact ID
 	if(*artifact != ""){
-		*artifact = *artifact + env.Suffix
+		*artifact = env.ArtifactId(*artifact)
 	}
 	
 	//fmt.Println(globalLandscape)
@@ -126,6 +131,32 @@ func initConfig() {
 	//	fmt.Println(pkg.Id)
 	//}
 
+	initViper()
+}
+
+//Command works only with landscape files and does not connect to systems
+func isLandscapeFileCommand() bool {
+	command, _, err := rootCmd.Find(os.Args[1:])
+	return err == nil && command == configRenderCmd
+}
+
+//Command generates landscape file, so it can run without it
+func isInitCommand() bool {
+	command, _, err := rootCmd.Find(os.Args[1:])
+	return err == nil && command == initCmd
+}
+
+//Get landscape configuration path
+func getLandscapeFilePath() string {
+	if *landscapeFile  != "" {
+		return *landscapeFile 
+	}
+	//Default landscape file location
+	return "conf/landscape.yaml"
+}
+
+//Read config file and ENV variables
+func initViper() {
 	//log.Println(cfgFile)
 	if cfgFile != "" {
 		// Use config file from the flag.
@@ -0,0 +1,232 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/landscape"
+	"github.com/Trifolium-project/landscaper/packages/util"
+	"github.com/spf13/cobra"
+)
+
+//Layout of snapshot archive: snapshot.yaml, and exported package with state.yaml in folder of each package
+const (
+	snapshotFile      = "snapshot.yaml"
+	snapshotStateFile = "state.yaml"
+)
+
+type SnapshotYAML struct {
+	Environment string   `yaml:"environment"`
+	System      string   `yaml:"system"`
+	Created     string   `yaml:"created"`
+	Packages    []string `yaml:"packages"`
+	//Packages, which were not saved, are listed with error, so incomplete snapshot is noticed on restore
+	Failed []SnapshotFailedPackageYAML `yaml:"failed,omitempty"`
+}
+
+type SnapshotFailedPackageYAML struct {
+	Id    string `yaml:"id"`
+	Error string `yaml:"error"`
+}
+
+//Tenant state of package, which is not part of exported package
+type SnapshotPackageStateYAML struct {
+	CustomTags []SnapshotCustomTagYAML `yaml:"customTags,omitempty"`
+	Deployed   []SnapshotDeployedYAML  `yaml:"deployed,omitempty"`
+}
+
+type SnapshotCustomTagYAML struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+//Deployed artifact, ID is stored as in original environment
+type SnapshotDeployedYAML struct {
+	Id      string `yaml:"id"`
+	Type    string `yaml:"type"`
+	Version string `yaml:"version"`
+	Status  string `yaml:"status"`
+}
+
+var snapshotOut *string
+var snapshotExclude *[]string
+
+// snapshotCmd represents the snapshot command
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Save all packages of environment into archive",
+	Long: `Save all packages of --env environment into tar.gz archive: package metadata, integration flows and value mappings with content
+and configuration, custom tags and list of deployed artifacts. Packages, which follow naming convention of other environment
+on the same system, are skipped. IDs and names are stored as in original environment, so snapshot can be restored into other environment.
+Values of parameters, which are declared as secrets in landscape file, are not saved.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		snapshot()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+
+	snapshotOut = snapshotCmd.Flags().String("out", "", "Path to snapshot archive(tar.gz)")
+	snapshotExclude = snapshotCmd.Flags().StringSlice("exclude", []string{}, "List of packages, which are not saved, glob patterns are supported")
+
+	snapshotCmd.MarkFlagRequired("out")
+}
+
+func snapshot() {
+	if globalLandscape == nil {
+		println("Global landscape is not instantiated")
+		return
+	}
+
+	currentEnvironment, err := globalLandscape.GetEnvironment(*environment)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	client := currentEnvironment.System.Client
+
+	integrationPackages, err := client.ReadIntegrationPackages()
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	dir, err := os.MkdirTemp("", "landscaper-snapshot")
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer os.RemoveAll(dir)
+
+	snapshotYAML := &SnapshotYAML{
+		Environment: currentEnvironment.Id,
+		System:      currentEnvironment.System.Host,
+		Created:     time.Now().Format(time.RFC3339),
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "#\tPackageId\tIntegration flows\tValue mappings\tDeployed\tCustom tags")
+
+	for _, integrationPackage := range integrationPackages {
+		if globalLandscape.GetPackageEnvironment(currentEnvironment.System, integrationPackage.Id) != currentEnvironment {
+			continue
+		}
+		if len(*snapshotExclude) > 0 {
+			excluded, err := matchesAnyPattern(integrationPackage.Id, *snapshotExclude)
+			if err != nil {
+				log.Fatalln(err)
+			}
+			if excluded {
+				continue
+			}
+		}
+
+		basePackageId := currentEnvironment.BasePackageId(integrationPackage.Id)
+		packageDir := filepath.Join(dir, basePackageId)
+
+		//Failed package is left out of snapshot, other packages are still saved
+		exported, state, err := snapshotPackage(currentEnvironment, integrationPackage.Id, packageDir)
+		if err != nil {
+			log.Printf("Unable to save package %s: %s", integrationPackage.Id, err)
+			os.RemoveAll(packageDir)
+			snapshotYAML.Failed = append(snapshotYAML.Failed, SnapshotFailedPackageYAML{Id: basePackageId, Error: err.Error()})
+			continue
+		}
+		snapshotYAML.Packages = append(snapshotYAML.Packages, basePackageId)
+
+		iflows := 0
+		for _, exportedArtifact := range exported {
+			if exportedArtifact.Type == artifactTypeIflow {
+				iflows++
+			}
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%d\t%d\t%d\t%d\n", len(snapshotYAML.Packages), integrationPackage.Id, iflows, len(exported)-iflows, len(state.Deployed), len(state.CustomTags))
+	}
+
+	if err := writeYAMLFile(filepath.Join(dir, snapshotFile), snapshotYAML); err != nil {
+		log.Fatalln(err)
+	}
+	if err := util.TarGzDirectory(dir, *snapshotOut); err != nil {
+		log.Fatalln(err)
+	}
+
+	writer.Flush()
+	fmt.Printf("\nSnapshot of %s is saved to %s\n", currentEnvironment.Id, *snapshotOut)
+
+	if len(snapshotYAML.Failed) > 0 {
+		fmt.Printf("\n%d packages are not saved:\n", len(snapshotYAML.Failed))
+		for _, failed := range snapshotYAML.Failed {
+			fmt.Printf("%s: %s\n", failed.Id, failed.Error)
+		}
+		os.Exit(1)
+	}
+}
+
+//Export package with content into directory, and save its tenant state next to it
+func snapshotPackage(env *landscape.Environment, packageId string, packageDir string) ([]*exportedArtifact, *SnapshotPackageStateYAML, error) {
+	exported, err := exportPackage(env, packageId, packageDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	state, err := readPackageState(env, packageId, exported)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := writeYAMLFile(filepath.Join(packageDir, snapshotStateFile), state); err != nil {
+		return nil, nil, err
+	}
+	return exported, state, nil
+}
+
+//Read custom tags of package, and runtime state of exported artifacts
+func readPackageState(env *landscape.Environment, packageId string, exported []*exportedArtifact) (*SnapshotPackageStateYAML, error) {
+	client := env.System.Client
+	state := &SnapshotPackageStateYAML{}
+
+	//Custom tags are not supported by every tenant
+	customTags, err := client.ReadIntegrationPackageCustomTags(packageId)
+	if err != nil {
+		log.Printf("Unable to read custom tags of %s, tags are not saved: %s", packageId, err)
+	}
+	for _, customTag := range customTags {
+		if customTag.Value != "" {
+			state.CustomTags = append(state.CustomTags, SnapshotCustomTagYAML{Name: customTag.Name, Value: customTag.Value})
+		}
+	}
+
+	for _, exportedArtifact := range exported {
+		runtimeArtifact, err := client.ReadIntegrationRuntimeArtifact(env.ArtifactId(exportedArtifact.Id))
+		if cpiclient.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		state.Deployed = append(state.Deployed, SnapshotDeployedYAML{
+			Id:      exportedArtifact.Id,
+			Type:    exportedArtifact.Type,
+			Version: runtimeArtifact.Version,
+			Status:  runtimeArtifact.Status,
+		})
+	}
+
+	return state, nil
+}
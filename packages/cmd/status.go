@@ -0,0 +1,247 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/landscape"
+	"github.com/Trifolium-project/landscaper/packages/util"
+	"github.com/spf13/cobra"
+)
+
+var statusOutput *string
+
+//Deployed artifact of environment
+type statusEntry struct {
+	Environment       string `json:"environment"`
+	Id                string `json:"id"`
+	Type              string `json:"type"`
+	PackageId         string `json:"packageId"`
+	Status            string `json:"status"`
+	Version           string `json:"version"`
+	DesigntimeVersion string `json:"designtimeVersion"`
+	DeployedBy        string `json:"deployedBy"`
+	DeployedOn        string `json:"deployedOn"`
+	//Design-time version is newer than deployed one
+	Outdated bool   `json:"outdated"`
+	Error    string `json:"error,omitempty"`
+}
+
+//Design-time artifact, to which runtime artifact belongs
+type designtimeVersion struct {
+	PackageId string
+	Version   string
+}
+
+// statusCmd represents the status command
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show deployed artifacts of environments",
+	Long: `Show all deployed artifacts of environments with runtime status, version, deployment user and time, and error summary.
+Artifacts, which have newer version in design time than deployed one, are flagged.
+All environments are shown, unless --env is set. Environments on the same system are separated by naming convention of IDs.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		status(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	statusOutput = statusCmd.Flags().StringP("output", "o", "text", "Output format: text or json")
+}
+
+func status(cmd *cobra.Command) {
+	if globalLandscape == nil {
+		println("Global landscape is not instantiated")
+		return
+	}
+
+	if *statusOutput != "text" && *statusOutput != "json" {
+		log.Fatalf("Unknown output format %s, use text or json\n", *statusOutput)
+	}
+
+	environments := selectEnvironments(cmd)
+	if !cmd.Flag("env").Changed {
+		environments = append([]*landscape.Environment{globalLandscape.OriginalEnvironment}, environments...)
+	}
+
+	//Several environments can share one system, runtime artifacts are read once per system
+	runtimeArtifactCache := make(map[*landscape.System][]*cpiclient.IntegrationRuntimeArtifact)
+
+	var entries []*statusEntry
+	for _, env := range environments {
+		runtimeArtifacts, ok := runtimeArtifactCache[env.System]
+		if !ok {
+			var err error
+			runtimeArtifacts, err = env.System.Client.ReadIntegrationRuntimeArtifacts()
+			if err != nil {
+				log.Fatalf("Unable to read runtime artifacts of %s: %s", env.Id, err)
+			}
+			runtimeArtifactCache[env.System] = runtimeArtifacts
+		}
+
+		environmentEntries, err := getEnvironmentStatus(env, runtimeArtifacts)
+		if err != nil {
+			log.Fatalln(err)
+		}
+		entries = append(entries, environmentEntries...)
+	}
+
+	if *statusOutput == "json" {
+		content, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			log.Fatalln(err)
+		}
+		fmt.Println(string(content))
+		return
+	}
+	printStatus(environments, entries)
+}
+
+//Status of runtime artifacts, which belong to environment
+func getEnvironmentStatus(env *landscape.Environment, runtimeArtifacts []*cpiclient.IntegrationRuntimeArtifact) ([]*statusEntry, error) {
+	client := env.System.Client
+
+	designtimeVersions, err := readDesigntimeVersions(env)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []*statusEntry
+	for _, runtimeArtifact := range runtimeArtifacts {
+		if globalLandscape.GetArtifactEnvironment(env.System, runtimeArtifact.Id) != env {
+			continue
+		}
+
+		entry := &statusEntry{
+			Environment:       env.Id,
+			Id:                runtimeArtifact.Id,
+			Type:              runtimeArtifact.Type,
+			PackageId:         "-",
+			Status:            runtimeArtifact.Status,
+			Version:           runtimeArtifact.Version,
+			DesigntimeVersion: "-",
+			DeployedBy:        valueOrDash(runtimeArtifact.DeployedBy, runtimeArtifact.DeployedBy != ""),
+			DeployedOn:        "-",
+		}
+		if !runtimeArtifact.DeployedAt.IsZero() {
+			entry.DeployedOn = runtimeArtifact.DeployedAt.Local().Format("2006-01-02 15:04:05")
+		}
+
+		if designtime, ok := designtimeVersions[runtimeArtifact.Id]; ok {
+			entry.PackageId = designtime.PackageId
+			entry.DesigntimeVersion = designtime.Version
+			//Draft version cannot be compared with deployed one
+			entry.Outdated = !strings.EqualFold(designtime.Version, "active") && util.CompareVersions(designtime.Version, runtimeArtifact.Version) > 0
+		}
+
+		if runtimeArtifact.Status == "ERROR" {
+			entry.Error, err = client.ReadIntegrationRuntimeArtifactErrorInformation(runtimeArtifact.Id)
+			if err != nil {
+				entry.Error = fmt.Sprintf("unable to read error information: %s", err)
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].PackageId != entries[j].PackageId {
+			return entries[i].PackageId < entries[j].PackageId
+		}
+		return entries[i].Id < entries[j].Id
+	})
+
+	return entries, nil
+}
+
+//Versions of integration flows and value mappings in packages of environment
+func readDesigntimeVersions(env *landscape.Environment) (map[string]*designtimeVersion, error) {
+	client := env.System.Client
+
+	integrationPackages, err := client.ReadIntegrationPackages()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read packages of %s: %s", env.Id, err)
+	}
+
+	versions := make(map[string]*designtimeVersion)
+	for _, integrationPackage := range integrationPackages {
+		if globalLandscape.GetPackageEnvironment(env.System, integrationPackage.Id) != env {
+			continue
+		}
+
+		designtimeArtifacts, err := client.ReadIntegrationDesigntimeArtifacts(integrationPackage.Id, false)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read artifacts of package %s: %s", integrationPackage.Id, err)
+		}
+		for _, designtimeArtifact := range designtimeArtifacts {
+			versions[designtimeArtifact.Id] = &designtimeVersion{PackageId: integrationPackage.Id, Version: designtimeArtifact.Version}
+		}
+
+		valueMappings, err := client.ReadValueMappingDesigntimeArtifacts(integrationPackage.Id)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read value mappings of package %s: %s", integrationPackage.Id, err)
+		}
+		for _, valueMapping := range valueMappings {
+			versions[valueMapping.Id] = &designtimeVersion{PackageId: integrationPackage.Id, Version: valueMapping.Version}
+		}
+	}
+	return versions, nil
+}
+
+func printStatus(environments []*landscape.Environment, entries []*statusEntry) {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "#\tEnvironment\tArtifactId\tType\tPackage\tStatus\tVersion\tDesign time\tDeployed by\tDeployed on\tNote")
+
+	for index, entry := range entries {
+		note := "-"
+		if entry.Outdated {
+			note = fmt.Sprintf("NEWER VERSION %s IN DESIGN TIME", entry.DesigntimeVersion)
+		}
+		if entry.Error != "" {
+			note = entry.Error
+		}
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", index+1, entry.Environment, entry.Id, entry.Type, entry.PackageId,
+			entry.Status, entry.Version, entry.DesigntimeVersion, entry.DeployedBy, entry.DeployedOn, note)
+	}
+	writer.Flush()
+
+	fmt.Println()
+	for _, env := range environments {
+		counts := make(map[string]int)
+		deployed := 0
+		for _, entry := range entries {
+			if entry.Environment != env.Id {
+				continue
+			}
+			deployed++
+			counts[entry.Status]++
+			if entry.Outdated {
+				counts["OUTDATED"]++
+			}
+		}
+		fmt.Printf("%s: %d deployed, %d started, %d starting, %d error, %d outdated\n", env.Id, deployed, counts["STARTED"], counts["STARTING"], counts["ERROR"], counts["OUTDATED"])
+	}
+}
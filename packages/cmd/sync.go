@@ -0,0 +1,512 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/iflow"
+	"github.com/Trifolium-project/landscaper/packages/landscape"
+	"github.com/Trifolium-project/landscaper/packages/util"
+	"github.com/spf13/cobra"
+)
+
+//Actions, which converge tenant to directory
+const (
+	syncCreate    = "CREATE"
+	syncUpdate    = "UPDATE"
+	syncConfigure = "CONFIGURE"
+	syncDeploy    = "DEPLOY"
+	syncDelete    = "DELETE"
+)
+
+var syncDir *string
+var syncDryRun *bool
+var syncPrune *bool
+var syncToDeploy *bool
+var syncTimeout *time.Duration
+
+//Planned change of tenant
+type syncAction struct {
+	PackageId string
+	Type      string
+	Id        string
+	Action    string
+	Details   string
+	apply     func() error
+}
+
+// syncCmd represents the sync command
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Converge tenant to packages in local directory",
+	Long: `Compare packages in local directory, written by package export, with --env environment, and create, update,
+configure and deploy packages, integration flows and value mappings, until tenant matches directory.
+Directory can contain one exported package, or folders with exported packages, e.g. checkout of git repository.
+Version and content are compared file by file except parameters.prop, configuration is taken from directory and from landscape file, so repeated sync changes nothing.
+Use --dry-run to show plan without changes, and --prune to delete artifacts, which are not found in directory, from synchronized packages.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		syncEnvironment()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+
+	syncDir = syncCmd.Flags().String("dir", "", "Directory with exported packages")
+	syncDryRun = syncCmd.Flags().Bool("dry-run", false, "Show planned changes without applying them")
+	syncPrune = syncCmd.Flags().Bool("prune", false, "Delete artifacts, which are not found in directory, from synchronized packages")
+	syncToDeploy = syncCmd.Flags().Bool("deploy", true, "Deploy changed artifacts, and artifacts, which are not deployed or failed")
+	syncTimeout = syncCmd.Flags().Duration("timeout", 5*time.Minute, "Maximum time to wait for undeploy of pruned artifacts")
+
+	syncCmd.MarkFlagRequired("dir")
+}
+
+func syncEnvironment() {
+	if globalLandscape == nil {
+		println("Global landscape is not instantiated")
+		return
+	}
+
+	currentEnvironment, err := globalLandscape.GetEnvironment(*environment)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	packageDirs, err := findExportedPackages(*syncDir)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	var actions []*syncAction
+	for _, packageDir := range packageDirs {
+		packageActions, err := planPackageSync(currentEnvironment, packageDir)
+		if err != nil {
+			log.Fatalf("Unable to plan synchronization of %s: %s", packageDir, err)
+		}
+		actions = append(actions, packageActions...)
+	}
+
+	if len(actions) == 0 {
+		fmt.Printf("Environment %s is in sync with %s\n", currentEnvironment.Id, *syncDir)
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 8, 1, '\t', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "#\tPackage\tType\tArtifactId\tAction\tDetails")
+	for index, action := range actions {
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\t%s\t%s\n", index+1, action.PackageId, action.Type, action.Id, action.Action, action.Details)
+	}
+	writer.Flush()
+
+	if *syncDryRun {
+		fmt.Printf("\nDry run: %d changes planned, nothing is applied\n", len(actions))
+		return
+	}
+
+	for _, action := range actions {
+		if err := action.apply(); err != nil {
+			log.Fatalf("%s of %s %s failed: %s", action.Action, action.Type, action.Id, err)
+		}
+	}
+	fmt.Printf("\nApplied %d changes to %s\n", len(actions), currentEnvironment.Id)
+}
+
+//Directory with package.yaml, or its subfolders with package.yaml
+func findExportedPackages(dir string) ([]string, error) {
+	if _, err := os.Stat(filepath.Join(dir, exportPackageFile)); err == nil {
+		return []string{dir}, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var packageDirs []string
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		packageDir := filepath.Join(dir, entry.Name())
+		if _, err := os.Stat(filepath.Join(packageDir, exportPackageFile)); err == nil {
+			packageDirs = append(packageDirs, packageDir)
+		}
+	}
+	sort.Strings(packageDirs)
+
+	if len(packageDirs) == 0 {
+		return nil, fmt.Errorf("no exported packages are found in %s", dir)
+	}
+	return packageDirs, nil
+}
+
+//Compare exported package with tenant. Value mappings are synchronized before integration flows
+func planPackageSync(env *landscape.Environment, dir string) ([]*syncAction, error) {
+	client := env.System.Client
+
+	descriptor, exported, err := readExportedPackage(dir)
+	if err != nil {
+		return nil, err
+	}
+	desiredPackage := newIntegrationPackage(env, descriptor)
+	packageId := desiredPackage.Id
+
+	var actions []*syncAction
+	newAction := func(artifactType string, id string, action string, details string, apply func() error) {
+		actions = append(actions, &syncAction{PackageId: packageId, Type: artifactType, Id: id, Action: action, Details: details, apply: apply})
+	}
+
+	applyPackage := func() error {
+		_, _, err := createOrUpdatePackage(env, descriptor)
+		return err
+	}
+
+	tenantIflows := make(map[string]*cpiclient.IntegrationDesigntimeArtifact)
+	tenantValueMappings := make(map[string]*cpiclient.ValueMappingDesigntimeArtifact)
+
+	tenantPackage, err := client.ReadIntegrationPackage(packageId)
+	if cpiclient.IsNotFound(err) {
+		newAction("PACKAGE", packageId, syncCreate, "-", applyPackage)
+	} else if err != nil {
+		return nil, err
+	} else {
+		if changed := changedPackageFields(desiredPackage, tenantPackage); len(changed) > 0 {
+			newAction("PACKAGE", packageId, syncUpdate, strings.Join(changed, ","), applyPackage)
+		}
+
+		iflows, err := client.ReadIntegrationDesigntimeArtifacts(packageId, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, tenantIflow := range iflows {
+			tenantIflows[tenantIflow.Id] = tenantIflow
+		}
+		valueMappings, err := client.ReadValueMappingDesigntimeArtifacts(packageId)
+		if err != nil {
+			return nil, err
+		}
+		for _, tenantValueMapping := range valueMappings {
+			tenantValueMappings[tenantValueMapping.Id] = tenantValueMapping
+		}
+	}
+
+	desiredIds := make(map[string]bool)
+	for _, exportedArtifact := range exported {
+		exportedArtifact := exportedArtifact
+		id := env.ArtifactId(exportedArtifact.Id)
+		desiredIds[id] = true
+
+		//Content of new or changed artifact is uploaded
+		changed := false
+		tenantVersion := ""
+		if exportedArtifact.Type == artifactTypeValueMapping {
+			applyContent := func() error {
+				_, _, err := importValueMapping(env, packageId, exportedArtifact)
+				return err
+			}
+			tenantValueMapping, ok := tenantValueMappings[id]
+			if !ok {
+				newAction(exportedArtifact.Type, id, syncCreate, "-", applyContent)
+				changed = true
+			} else {
+				tenantVersion = tenantValueMapping.Version
+				downloaded, err := client.DownloadValueMappingDesigntimeArtifact(id, tenantValueMapping.Version)
+				if err != nil {
+					return nil, err
+				}
+				files, err := compareExportedContent(exportedArtifact, tenantVersion, downloaded.ArtifactContent)
+				if err != nil {
+					return nil, err
+				}
+				if len(files) > 0 {
+					newAction(exportedArtifact.Type, id, syncUpdate, strings.Join(files, ","), applyContent)
+					changed = true
+				}
+			}
+		} else {
+			applyContent := func() error {
+				_, _, err := uploadExportedIflow(env, packageId, exportedArtifact)
+				return err
+			}
+			tenantIflow, ok := tenantIflows[id]
+			if !ok {
+				newAction(exportedArtifact.Type, id, syncCreate, "-", applyContent)
+				changed = true
+			} else {
+				tenantVersion = tenantIflow.Version
+				downloaded, err := client.DownloadIntegrationDesigntimeArtifact(id, tenantIflow.Version)
+				if err != nil {
+					return nil, err
+				}
+				files, err := compareExportedContent(exportedArtifact, tenantVersion, downloaded.ArtifactContent)
+				if err != nil {
+					return nil, err
+				}
+				if len(files) > 0 {
+					newAction(exportedArtifact.Type, id, syncUpdate, strings.Join(files, ","), applyContent)
+					changed = true
+				}
+			}
+
+			//Configuration of uploaded artifact is checked, when it is applied
+			configurations, err := getImportConfigurations(env, descriptor.Id, exportedArtifact)
+			if err != nil {
+				return nil, fmt.Errorf("configuration of %s: %s", id, err)
+			}
+			applyConfiguration := func() error {
+				return applyImportConfigurations(client, id, configurations)
+			}
+			if changed && len(configurations) > 0 {
+				newAction(exportedArtifact.Type, id, syncConfigure, "after upload", applyConfiguration)
+			}
+			if !changed {
+				if keys := changedConfigurationKeys(configurations, tenantIflow.Configurations); len(keys) > 0 {
+					newAction(exportedArtifact.Type, id, syncConfigure, strings.Join(keys, ","), applyConfiguration)
+					changed = true
+				}
+			}
+		}
+
+		if !*syncToDeploy {
+			continue
+		}
+		reason, err := getDeployReason(client, id, tenantVersion, changed)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			artifactType := exportedArtifact.Type
+			newAction(artifactType, id, syncDeploy, reason, func() error {
+				return deployImportedArtifact(client, artifactType, id)
+			})
+		}
+	}
+
+	if !*syncPrune {
+		return actions, nil
+	}
+
+	var prunedValueMappings []string
+	for id := range tenantValueMappings {
+		if !desiredIds[id] {
+			prunedValueMappings = append(prunedValueMappings, id)
+		}
+	}
+	sort.Strings(prunedValueMappings)
+	for _, id := range prunedValueMappings {
+		id := id
+		version := tenantValueMappings[id].Version
+		newAction(artifactTypeValueMapping, id, syncDelete, "not found in directory", func() error {
+			if err := undeployPrunedArtifact(client, id); err != nil {
+				return err
+			}
+			return client.DeleteValueMappingDesigntimeArtifact(id, version)
+		})
+	}
+	var prunedIflows []string
+	for id := range tenantIflows {
+		if !desiredIds[id] {
+			prunedIflows = append(prunedIflows, id)
+		}
+	}
+	sort.Strings(prunedIflows)
+	for _, id := range prunedIflows {
+		id := id
+		version := tenantIflows[id].Version
+		newAction(artifactTypeIflow, id, syncDelete, "not found in directory", func() error {
+			if err := undeployPrunedArtifact(client, id); err != nil {
+				return err
+			}
+			return client.DeleteIntegrationDesigntimeArtifact(id, version)
+		})
+	}
+
+	return actions, nil
+}
+
+//Names of package attributes, which differ in tenant
+func changedPackageFields(desired *cpiclient.IntegrationPackage, tenant *cpiclient.IntegrationPackage) []string {
+	fields := []struct {
+		name    string
+		desired string
+		tenant  string
+	}{
+		{"Name", desired.Name, tenant.Name},
+		{"ShortText", desired.ShortText, tenant.ShortText},
+		{"Description", desired.Description, tenant.Description},
+		{"Vendor", desired.Vendor, tenant.Vendor},
+		{"Version", desired.Version, tenant.Version},
+		{"Keywords", desired.Keywords, tenant.Keywords},
+		{"Products", desired.Products, tenant.Products},
+		{"Countries", desired.Countries, tenant.Countries},
+		{"Industries", desired.Industries, tenant.Industries},
+		{"LineOfBusiness", desired.LineOfBusiness, tenant.LineOfBusiness},
+	}
+
+	var changed []string
+	for _, field := range fields {
+		if field.desired != field.tenant {
+			changed = append(changed, field.name)
+		}
+	}
+	return changed
+}
+
+//Version and files of exported artifact, which differ from base64 encoded content in tenant
+func compareExportedContent(exported *exportedArtifact, tenantVersion string, tenantContent string) ([]string, error) {
+	content, err := exported.content()
+	if err != nil {
+		return nil, fmt.Errorf("unable to pack %s: %s", exported.Id, err)
+	}
+	localEntries, err := util.ReadZipEntries(content)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(tenantContent)
+	if err != nil {
+		return nil, err
+	}
+	tenantEntries, err := util.ReadZipEntries(decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+	if exported.Version != tenantVersion {
+		changed = append(changed, "version")
+	}
+	return append(changed, changedExportedEntries(tenantEntries, localEntries)...), nil
+}
+
+//Files, which differ in tenant and export. Values of parameters.prop are configured separately, so it is ignored.
+//Integration flow file and symbolic name in manifest follow artifact ID, properties files are compared by values
+func changedExportedEntries(tenantEntries map[string][]byte, localEntries map[string][]byte) []string {
+	tenant := normalizeExportedEntries(tenantEntries)
+	local := normalizeExportedEntries(localEntries)
+
+	var modified []string
+	for name, data := range local {
+		if tenantData, ok := tenant[name]; !ok || !equalExportedEntries(name, tenantData, data) {
+			modified = append(modified, name)
+		}
+	}
+	for name := range tenant {
+		if _, ok := local[name]; !ok {
+			modified = append(modified, name)
+		}
+	}
+	sort.Strings(modified)
+
+	return modified
+}
+
+func normalizeExportedEntries(entries map[string][]byte) map[string][]byte {
+	normalized := make(map[string][]byte)
+	for name, data := range entries {
+		if path.Base(name) == "parameters.prop" {
+			continue
+		}
+		if path.Ext(name) == ".iflw" {
+			name = path.Join(path.Dir(name), "*.iflw")
+		}
+		normalized[name] = data
+	}
+	return normalized
+}
+
+func equalExportedEntries(name string, tenant []byte, local []byte) bool {
+	switch {
+	case path.Base(name) == "MANIFEST.MF":
+		tenantAttributes := iflow.ParseManifest(tenant).Attributes
+		localAttributes := iflow.ParseManifest(local).Attributes
+		for _, attributes := range []map[string]string{tenantAttributes, localAttributes} {
+			delete(attributes, "Bundle-SymbolicName")
+			delete(attributes, "Bundle-Name")
+		}
+		return reflect.DeepEqual(tenantAttributes, localAttributes)
+	case path.Ext(name) == ".prop":
+		_, tenantValues := iflow.ParseProperties(tenant)
+		_, localValues := iflow.ParseProperties(local)
+		return reflect.DeepEqual(tenantValues, localValues)
+	default:
+		return string(tenant) == string(local)
+	}
+}
+
+//Keys of parameters, which have different value in tenant. Keys, which are not found in tenant, are skipped
+func changedConfigurationKeys(configurations []*cpiclient.Configuration, tenant []*cpiclient.Configuration) []string {
+	var keys []string
+	for _, conf := range configurations {
+		tenantConf, err := getConfiguration(conf.ParameterKey, tenant)
+		if err == nil && tenantConf.ParameterValue != conf.ParameterValue {
+			keys = append(keys, conf.ParameterKey)
+		}
+	}
+	return keys
+}
+
+//Reason to deploy artifact: it is changed, not deployed, failed, or other version is deployed. Empty, if deploy is not needed
+func getDeployReason(client *cpiclient.CPIClient, id string, designtimeVersion string, changed bool) (string, error) {
+	if changed {
+		return "changed", nil
+	}
+
+	runtimeArtifact, err := client.ReadIntegrationRuntimeArtifact(id)
+	if cpiclient.IsNotFound(err) {
+		return "not deployed", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if runtimeArtifact.Status == "ERROR" {
+		return "status ERROR", nil
+	}
+	//Draft version cannot be compared with deployed one
+	if !strings.EqualFold(designtimeVersion, "active") && runtimeArtifact.Version != designtimeVersion {
+		return fmt.Sprintf("version %s is deployed", runtimeArtifact.Version), nil
+	}
+	return "", nil
+}
+
+//Undeploy artifact, if it is deployed, and wait until it is removed from runtime
+func undeployPrunedArtifact(client *cpiclient.CPIClient, id string) error {
+	_, err := client.ReadIntegrationRuntimeArtifact(id)
+	if cpiclient.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := client.UndeployIntegrationRuntimeArtifact(id); err != nil {
+		return err
+	}
+	return client.WaitForIntegrationRuntimeArtifactRemoval(id, *syncTimeout)
+}
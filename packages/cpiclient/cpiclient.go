@@ -28,10 +28,12 @@ import (
 	"net/http"
 	"net/http/cookiejar"
 	"net/http/httptrace"
+	"time"
 )
 
 const (
-	apiVersion = "v1"
+	apiVersion   = "v1"
+	pollInterval = 5 * time.Second
 )
 
 
@@ -90,6 +92,8 @@ type IntegrationRuntimeArtifact struct {
 	DeployedBy		string
 	DeployedOn		string
 	Status			string
+	//DeployedOn in /Date(ms)/ format, parsed
+	DeployedAt		time.Time
 }
 /*
 
@@ -134,6 +138,24 @@ type Configuration struct {
 	ParameterKey   string
 	ParameterValue string
 	DataType       string
+	//Value is secret and is masked in logs
+	Sensitive bool `json:"-"`
+}
+
+//Unsuccessful response of CPI API
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return e.Body
+}
+
+//Check whether requested entity does not exist in tenant
+func IsNotFound(err error) bool {
+	httpError, ok := err.(*HTTPError)
+	return ok && httpError.StatusCode == http.StatusNotFound
 }
 
 func NewCPIBasicAuthClient(username, password, url string, verbose bool) *CPIClient {
@@ -190,7 +212,7 @@ func (s *CPIClient) doRequest(req *http.Request) ([]byte, http.Header, error) {
 	}
 	resp.Body.Close()
 	if s.VerboseLog {
-		log.Printf("Response: %s", resp)
+		log.Printf("Response: %v", resp)
 		log.Printf("\n\n")
 	}
 
@@ -200,7 +222,7 @@ func (s *CPIClient) doRequest(req *http.Request) ([]byte, http.Header, error) {
 
 	if httpCodeGroup != 2 {
 
-		return nil, nil, fmt.Errorf("%s", body)
+		return nil, nil, &HTTPError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 	return body, resp.Header, nil
 }
@@ -235,6 +257,14 @@ func (s *CPIClient) UpdateIntegrationDesigntimeArtifactConfiguration(ArtifactId
 		return err
 	}
 
+	if s.VerboseLog {
+		value := configuration.ParameterValue
+		if configuration.Sensitive {
+			value = "****"
+		}
+		log.Printf("Update parameter %s of %s: %s", configuration.ParameterKey, ArtifactId, value)
+	}
+
 	req, err := http.NewRequestWithContext(s.traceCtx, http.MethodPut, url, bytes.NewBuffer(body))
 	//req, err := http.NewRequest("PUT", url, bytes.NewBuffer(body))
 	if err != nil {
@@ -322,21 +352,158 @@ func (s *CPIClient) ReadIntegrationRuntimeArtifact(ArtifactId string) (*Integrat
 
 	root := data["d"].((map[string]interface{}))
 
-	integrationArtifact := &IntegrationRuntimeArtifact{
-		Id:                root["Id"].(string),
-		Version:		   root["Version"].(string),
-		Name:              root["Name"].(string),
-		Type:			   root["Type"].(string),
-		DeployedBy:		   root["DeployedBy"].(string),
-		DeployedOn:		   root["DeployedOn"].(string),
-		Status:		       root["Status"].(string),
-
-	}
+	integrationArtifact := newIntegrationRuntimeArtifact(root)
 	
 	return integrationArtifact, nil
 
 }
 
+//All deployed artifacts of tenant: integration flows, value mappings and other types
+func (s *CPIClient) ReadIntegrationRuntimeArtifacts() ([]*IntegrationRuntimeArtifact, error) {
+	url := fmt.Sprintf("https://" + s.URL + "/api/" + apiVersion + "/" + "IntegrationRuntimeArtifacts" + "?$format=json")
+
+	req, err := http.NewRequestWithContext(s.traceCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	bytes, _, err := s.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+
+	err = json.Unmarshal(bytes, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	root := data["d"].((map[string]interface{}))
+	artifactsRawList := root["results"].([]interface{})
+
+	var runtimeArtifacts []*IntegrationRuntimeArtifact
+	for _, element := range artifactsRawList {
+		runtimeArtifacts = append(runtimeArtifacts, newIntegrationRuntimeArtifact(element.(map[string]interface{})))
+	}
+	return runtimeArtifacts, nil
+}
+
+//Runtime artifact from JSON entry. DeployedBy is empty for artifacts, which are deployed by tenant
+func newIntegrationRuntimeArtifact(artifactJson map[string]interface{}) *IntegrationRuntimeArtifact {
+	runtimeArtifact := &IntegrationRuntimeArtifact{
+		Id:      artifactJson["Id"].(string),
+		Version: artifactJson["Version"].(string),
+		Name:    artifactJson["Name"].(string),
+		Type:    artifactJson["Type"].(string),
+		Status:  artifactJson["Status"].(string),
+	}
+	runtimeArtifact.DeployedBy, _ = artifactJson["DeployedBy"].(string)
+	runtimeArtifact.DeployedOn, _ = artifactJson["DeployedOn"].(string)
+	runtimeArtifact.DeployedAt, _ = ParseODataDate(runtimeArtifact.DeployedOn)
+
+	return runtimeArtifact
+}
+
+//Read error of artifact, which failed to start. Short summary of error is returned
+func (s *CPIClient) ReadIntegrationRuntimeArtifactErrorInformation(ArtifactId string) (string, error) {
+	url := fmt.Sprintf("https://" + s.URL + "/api/" + apiVersion + "/" + "IntegrationRuntimeArtifacts('" +
+		ArtifactId + "')/ErrorInformation/$value")
+
+	req, err := http.NewRequestWithContext(s.traceCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	bytes, _, err := s.doRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	return ParseErrorInformation(bytes), nil
+}
+
+//Wait until runtime artifact is removed from tenant after undeploy
+func (s *CPIClient) WaitForIntegrationRuntimeArtifactRemoval(ArtifactId string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		_, err := s.ReadIntegrationRuntimeArtifact(ArtifactId)
+		if IsNotFound(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("runtime artifact %s is not removed after %s", ArtifactId, timeout)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+//Wait until provided version of runtime artifact reaches status, e.g. STARTED after deploy.
+//Deploy error is returned immediately. Empty version means any version
+func (s *CPIClient) WaitForIntegrationRuntimeArtifactStatus(ArtifactId string, ArtifactVersion string, status string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		runtimeArtifact, err := s.ReadIntegrationRuntimeArtifact(ArtifactId)
+		if err != nil && !IsNotFound(err) {
+			return err
+		}
+		//Runtime artifact does not exist or has previous version, until deploy is started
+		if err == nil && (ArtifactVersion == "" || runtimeArtifact.Version == ArtifactVersion) {
+			if runtimeArtifact.Status == status {
+				return nil
+			}
+			if runtimeArtifact.Status == "ERROR" {
+				return fmt.Errorf("runtime artifact %s %s is in status ERROR", ArtifactId, runtimeArtifact.Version)
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("runtime artifact %s has not reached status %s after %s", ArtifactId, status, timeout)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+//Wait until new deployment of artifact is started. Previous is runtime artifact before deploy, nil if artifact was not deployed.
+//Runtime artifact is accepted only if it is deployed after previous one or has another version, so artifact in status ERROR
+//can be redeployed. Error information is returned, if deployment fails
+func (s *CPIClient) WaitForIntegrationRuntimeArtifactDeployment(ArtifactId string, ArtifactVersion string, previous *IntegrationRuntimeArtifact, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		runtimeArtifact, err := s.ReadIntegrationRuntimeArtifact(ArtifactId)
+		if err != nil && !IsNotFound(err) {
+			return err
+		}
+		if err == nil && (ArtifactVersion == "" || runtimeArtifact.Version == ArtifactVersion) && isNewDeployment(runtimeArtifact, previous) {
+			if runtimeArtifact.Status == "STARTED" {
+				return nil
+			}
+			if runtimeArtifact.Status == "ERROR" {
+				errorInformation, err := s.ReadIntegrationRuntimeArtifactErrorInformation(ArtifactId)
+				if err != nil {
+					errorInformation = "error information is not available"
+				}
+				return fmt.Errorf("runtime artifact %s %s is in status ERROR: %s", ArtifactId, runtimeArtifact.Version, errorInformation)
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("runtime artifact %s has not started after %s", ArtifactId, timeout)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+//Runtime artifact is not the previous deployment. Deploy time, which is not returned by tenant, is not trusted
+func isNewDeployment(runtimeArtifact *IntegrationRuntimeArtifact, previous *IntegrationRuntimeArtifact) bool {
+	if previous == nil || runtimeArtifact.Version != previous.Version {
+		return true
+	}
+	return !runtimeArtifact.DeployedAt.IsZero() && runtimeArtifact.DeployedAt.After(previous.DeployedAt)
+}
+
 /*
 type IntegrationRuntimeArtifact struct {
 	Id              string
@@ -520,6 +687,41 @@ func (s *CPIClient) UploadIntegrationDesigntimeArtifact(integrationArtifact *Int
 	return nil
 }
 
+//Replace content of existing artifact
+func (s *CPIClient) UpdateIntegrationDesigntimeArtifact(integrationArtifact *IntegrationDesigntimeArtifact) error {
+
+	url := fmt.Sprintf("https://" + s.URL + "/api/" + apiVersion + "/" + "IntegrationDesigntimeArtifacts(Id='" +
+		integrationArtifact.Id + "',Version='active')")
+
+	body, err := json.Marshal(map[string]string{
+		"Name":            integrationArtifact.Name,
+		"ArtifactContent": integrationArtifact.ArtifactContent,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(s.traceCtx, http.MethodPut, url, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+
+	token, err := s.getCSRFToken()
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-CSRF-Token", token)
+	req.Header.Set("Content-Type", "application/json")
+
+	_, _, err = s.doRequest(req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 //IntegrationDesigntimeArtifact
 
 func (s *CPIClient) DeployIntegrationDesigntimeArtifact(ArtifactId string, ArtifactVersion string) error {
@@ -787,6 +989,36 @@ func (s *CPIClient) CreateIntegrationPackage(integrationPackage *IntegrationPack
 }
 
 
+//Update metadata of existing integration package
+func (s *CPIClient) UpdateIntegrationPackage(integrationPackage *IntegrationPackage) error {
+	url := fmt.Sprintf("https://" + s.URL + "/api/" + apiVersion + "/" + "IntegrationPackages('" + integrationPackage.Id + "')")
+
+	body, err := json.Marshal(integrationPackage)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(s.traceCtx, http.MethodPut, url, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+
+	token, err := s.getCSRFToken()
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-CSRF-Token", token)
+	req.Header.Set("Content-Type", "application/json")
+
+	_, _, err = s.doRequest(req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+
 func (s *CPIClient) CopyIntegrationPackageFromDiscover(DiscoverPackageId string) (*IntegrationPackage, error) {
 	url := fmt.Sprintf("https://" + s.URL + "/api/" + apiVersion + "/" + "CopyIntegrationPackage?" + "$format=json" + "&Id='" +  DiscoverPackageId + "'")
 
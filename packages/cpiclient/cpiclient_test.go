@@ -0,0 +1,61 @@
+package cpiclient
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseODataDate(t *testing.T) {
+	date, err := ParseODataDate("/Date(1521463557739)/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !date.Equal(time.Date(2018, 3, 19, 12, 45, 57, 739000000, time.UTC)) {
+		t.Error("Unexpected date ", date)
+	}
+
+	if _, err := ParseODataDate("/Date(1521463557739+0000)/"); err != nil {
+		t.Error(err)
+	}
+	if _, err := ParseODataDate("2018-03-19"); err == nil {
+		t.Error("Expected error for date in other format")
+	}
+}
+
+func TestParseErrorInformation(t *testing.T) {
+	content := `{"message":{"messageText":"Deployment failed"},"parameter":["Unable to find credential OrdersUser"],"childInstances":[]}`
+	if summary := ParseErrorInformation([]byte(content)); summary != "Unable to find credential OrdersUser" {
+		t.Error("Unexpected summary ", summary)
+	}
+
+	content = `{"message":{"messageText":""},"parameter":[],"childInstances":[{"message":{"messageText":"Script compilation failed"}}]}`
+	if summary := ParseErrorInformation([]byte(content)); summary != "Script compilation failed" {
+		t.Error("Unexpected summary ", summary)
+	}
+
+	if summary := ParseErrorInformation([]byte("Internal error\nat line 1")); summary != "Internal error" {
+		t.Error("Unexpected summary ", summary)
+	}
+}
+
+func TestIsNewDeployment(t *testing.T) {
+	deployedAt := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	previous := &IntegrationRuntimeArtifact{Version: "1.0.0", DeployedAt: deployedAt}
+
+	cases := []struct {
+		runtimeArtifact *IntegrationRuntimeArtifact
+		previous        *IntegrationRuntimeArtifact
+		expected        bool
+	}{
+		{&IntegrationRuntimeArtifact{Version: "1.0.0"}, nil, true},
+		{&IntegrationRuntimeArtifact{Version: "1.0.0", DeployedAt: deployedAt}, previous, false},
+		{&IntegrationRuntimeArtifact{Version: "1.0.0"}, previous, false},
+		{&IntegrationRuntimeArtifact{Version: "1.0.0", DeployedAt: deployedAt.Add(time.Minute)}, previous, true},
+		{&IntegrationRuntimeArtifact{Version: "1.0.1"}, previous, true},
+	}
+	for index, c := range cases {
+		if isNewDeployment(c.runtimeArtifact, c.previous) != c.expected {
+			t.Errorf("Case %d: expected %t", index, c.expected)
+		}
+	}
+}
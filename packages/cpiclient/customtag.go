@@ -0,0 +1,96 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cpiclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+//Custom tag of integration package. Tags are defined once per tenant, packages only have values
+type CustomTag struct {
+	Name  string
+	Value string
+}
+
+//CustomTags of package
+func (s *CPIClient) ReadIntegrationPackageCustomTags(PackageId string) ([]*CustomTag, error) {
+	url := fmt.Sprintf("https://" + s.URL + "/api/" + apiVersion + "/" + "IntegrationPackages('" + PackageId +
+		"')/CustomTags" + "?$format=json")
+
+	req, err := http.NewRequestWithContext(s.traceCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	bytes, _, err := s.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+
+	err = json.Unmarshal(bytes, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	root := data["d"].((map[string]interface{}))
+	tagsRawList := root["results"].([]interface{})
+
+	var customTags []*CustomTag
+	for _, element := range tagsRawList {
+		tagJson := element.(map[string]interface{})
+		customTag := &CustomTag{Name: tagJson["Name"].(string)}
+		//Value is null, if it is not set for package
+		customTag.Value, _ = tagJson["Value"].(string)
+
+		customTags = append(customTags, customTag)
+	}
+	return customTags, nil
+}
+
+//Set value of custom tag, tag should be defined in tenant
+func (s *CPIClient) UpdateIntegrationPackageCustomTag(PackageId string, customTag *CustomTag) error {
+	url := fmt.Sprintf("https://" + s.URL + "/api/" + apiVersion + "/" + "IntegrationPackages('" + PackageId +
+		"')/$links/CustomTags('" + customTag.Name + "')")
+
+	body, err := json.Marshal(map[string]string{"Value": customTag.Value})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(s.traceCtx, http.MethodPut, url, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+
+	token, err := s.getCSRFToken()
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-CSRF-Token", token)
+	req.Header.Set("Content-Type", "application/json")
+
+	_, _, err = s.doRequest(req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
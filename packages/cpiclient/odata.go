@@ -0,0 +1,101 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cpiclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Maximal length of error summary
+const errorSummaryLength = 200
+
+var odataDatePattern = regexp.MustCompile(`^/Date\((-?\d+)([+-]\d{4})?\)/$`)
+
+//Parse OData V2 date, e.g. /Date(1521463557739)/. Offset, if present, does not change the point in time
+func ParseODataDate(value string) (time.Time, error) {
+	match := odataDatePattern.FindStringSubmatch(value)
+	if match == nil {
+		return time.Time{}, fmt.Errorf("%s is not OData date", value)
+	}
+	milliseconds, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, milliseconds*int64(time.Millisecond)).UTC(), nil
+}
+
+//Get summary from error information of runtime artifact. Parameters of message contain error text,
+//message text is used, if there are no parameters. Unknown format is returned as is
+func ParseErrorInformation(content []byte) string {
+	var data map[string]interface{}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return summarizeError(string(content))
+	}
+
+	if summary := errorInformationText(data); summary != "" {
+		return summarizeError(summary)
+	}
+	return summarizeError(string(content))
+}
+
+func errorInformationText(data map[string]interface{}) string {
+	var parameters []string
+	if rawParameters, ok := data["parameter"].([]interface{}); ok {
+		for _, rawParameter := range rawParameters {
+			if parameter, ok := rawParameter.(string); ok && parameter != "" {
+				parameters = append(parameters, parameter)
+			}
+		}
+	}
+	if len(parameters) > 0 {
+		return strings.Join(parameters, "; ")
+	}
+
+	if message, ok := data["message"].(map[string]interface{}); ok {
+		if text, ok := message["messageText"].(string); ok && text != "" {
+			return text
+		}
+	}
+
+	//Error of deployed artifact can be reported by its child component
+	if children, ok := data["childInstances"].([]interface{}); ok {
+		for _, rawChild := range children {
+			if child, ok := rawChild.(map[string]interface{}); ok {
+				if text := errorInformationText(child); text != "" {
+					return text
+				}
+			}
+		}
+	}
+	return ""
+}
+
+//First line of error, limited in length
+func summarizeError(text string) string {
+	text = strings.TrimSpace(text)
+	if index := strings.IndexAny(text, "\r\n"); index >= 0 {
+		text = text[:index]
+	}
+	if len(text) > errorSummaryLength {
+		text = text[:errorSummaryLength] + "..."
+	}
+	return text
+}
@@ -0,0 +1,173 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cpiclient
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type ValueMappingDesigntimeArtifact struct {
+	Id              string
+	Version         string `json:"-"`
+	PackageId       string
+	Name            string
+	Description     string `json:"-"`
+	ArtifactContent string
+}
+
+//ValueMappingDesigntimeArtifacts of package
+func (s *CPIClient) ReadValueMappingDesigntimeArtifacts(PackageId string) ([]*ValueMappingDesigntimeArtifact, error) {
+	url := fmt.Sprintf("https://" + s.URL + "/api/" + apiVersion + "/" + "IntegrationPackages('" + PackageId +
+		"')/ValueMappingDesigntimeArtifacts" + "?$format=json")
+
+	req, err := http.NewRequestWithContext(s.traceCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	bytes, _, err := s.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var data map[string]interface{}
+
+	err = json.Unmarshal(bytes, &data)
+	if err != nil {
+		return nil, err
+	}
+
+	root := data["d"].((map[string]interface{}))
+	artifactsRawList := root["results"].([]interface{})
+
+	var valueMappings []*ValueMappingDesigntimeArtifact
+
+	for _, element := range artifactsRawList {
+		artifactJson := element.(map[string]interface{})
+		valueMapping := &ValueMappingDesigntimeArtifact{
+			Id:        artifactJson["Id"].(string),
+			Version:   artifactJson["Version"].(string),
+			PackageId: artifactJson["PackageId"].(string),
+			Name:      artifactJson["Name"].(string),
+		}
+		//Description is not returned by every tenant
+		valueMapping.Description, _ = artifactJson["Description"].(string)
+
+		valueMappings = append(valueMappings, valueMapping)
+	}
+	return valueMappings, nil
+}
+
+//Download content of value mapping. Metadata is not read, only Id, Version and content are filled
+func (s *CPIClient) DownloadValueMappingDesigntimeArtifact(ArtifactId string, ArtifactVersion string) (*ValueMappingDesigntimeArtifact, error) {
+	url := fmt.Sprintf("https://" + s.URL + "/api/" + apiVersion + "/" + "ValueMappingDesigntimeArtifacts(Id='" +
+		ArtifactId + "',Version='" + ArtifactVersion + "')/$value")
+
+	req, err := http.NewRequestWithContext(s.traceCtx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	bytes, _, err := s.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ValueMappingDesigntimeArtifact{
+		Id:              ArtifactId,
+		Version:         ArtifactVersion,
+		ArtifactContent: base64.StdEncoding.EncodeToString(bytes),
+	}, nil
+}
+
+func (s *CPIClient) UploadValueMappingDesigntimeArtifact(valueMapping *ValueMappingDesigntimeArtifact) error {
+	url := fmt.Sprintf("https://" + s.URL + "/api/" + apiVersion + "/" + "ValueMappingDesigntimeArtifacts")
+
+	body, err := json.Marshal(valueMapping)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(s.traceCtx, http.MethodPost, url, bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+
+	token, err := s.getCSRFToken()
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-CSRF-Token", token)
+	req.Header.Set("Content-Type", "application/json")
+
+	_, _, err = s.doRequest(req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *CPIClient) DeployValueMappingDesigntimeArtifact(ArtifactId string, ArtifactVersion string) error {
+	url := fmt.Sprintf("https://" + s.URL + "/api/" + apiVersion + "/" + "DeployValueMappingDesigntimeArtifact?Id='" +
+		ArtifactId + "'&Version='" + ArtifactVersion + "'")
+
+	req, err := http.NewRequestWithContext(s.traceCtx, http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+
+	token, err := s.getCSRFToken()
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-CSRF-Token", token)
+
+	_, _, err = s.doRequest(req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *CPIClient) DeleteValueMappingDesigntimeArtifact(ArtifactId string, ArtifactVersion string) error {
+	url := fmt.Sprintf("https://" + s.URL + "/api/" + apiVersion + "/" + "ValueMappingDesigntimeArtifacts(Id='" +
+		ArtifactId + "',Version='" + ArtifactVersion + "')")
+
+	req, err := http.NewRequestWithContext(s.traceCtx, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+
+	token, err := s.getCSRFToken()
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("X-CSRF-Token", token)
+
+	_, _, err = s.doRequest(req)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,275 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package iflow
+
+import (
+	"encoding/xml"
+	"regexp"
+	"sort"
+)
+
+//Reference to externalized parameter, e.g. {{Endpoint}}
+var parameterReferencePattern = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+//Integration flow model from .iflw file(BPMN 2.0 with SAP extensions)
+type IntegrationFlow struct {
+	Path                string
+	Name                string
+	Properties          map[string]string
+	Participants        []*Participant
+	Channels            []*Channel
+	Processes           []*Process
+	ScriptCollections   []string
+	ParameterReferences []string
+}
+
+//Sender or receiver system, or integration process
+type Participant struct {
+	Id         string
+	Name       string
+	Type       string
+	Properties map[string]string
+}
+
+//Message flow between participant and integration process, configured with adapter
+type Channel struct {
+	Id                string
+	Name              string
+	Source            string
+	Target            string
+	ComponentType     string
+	Direction         string
+	TransportProtocol string
+	MessageProtocol   string
+	Properties        map[string]string
+}
+
+//Integration process or local integration process
+type Process struct {
+	Id         string
+	Name       string
+	Properties map[string]string
+	Steps      []*Step
+}
+
+//Step of integration process, e.g. script, mapping or request-reply
+type Step struct {
+	Id           string
+	Name         string
+	Kind         string
+	ActivityType string
+	Properties   map[string]string
+}
+
+type propertyXML struct {
+	Key   string `xml:"key"`
+	Value string `xml:"value"`
+}
+
+type participantXML struct {
+	Id         string        `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Type       string        `xml:"type,attr"`
+	Properties []propertyXML `xml:"extensionElements>property"`
+}
+
+type messageFlowXML struct {
+	Id         string        `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	SourceRef  string        `xml:"sourceRef,attr"`
+	TargetRef  string        `xml:"targetRef,attr"`
+	Properties []propertyXML `xml:"extensionElements>property"`
+}
+
+type elementXML struct {
+	XMLName    xml.Name
+	Id         string        `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Properties []propertyXML `xml:"extensionElements>property"`
+	Elements   []elementXML  `xml:",any"`
+}
+
+type processXML struct {
+	Id         string        `xml:"id,attr"`
+	Name       string        `xml:"name,attr"`
+	Properties []propertyXML `xml:"extensionElements>property"`
+	Elements   []elementXML  `xml:",any"`
+}
+
+type definitionsXML struct {
+	Collaboration struct {
+		Name         string           `xml:"name,attr"`
+		Properties   []propertyXML    `xml:"extensionElements>property"`
+		Participants []participantXML `xml:"participant"`
+		MessageFlows []messageFlowXML `xml:"messageFlow"`
+	} `xml:"collaboration"`
+	Processes []processXML `xml:"process"`
+}
+
+//Parse .iflw file
+func ParseIntegrationFlow(content []byte) (*IntegrationFlow, error) {
+	definitions := definitionsXML{}
+	err := xml.Unmarshal(content, &definitions)
+	if err != nil {
+		return nil, err
+	}
+
+	flow := &IntegrationFlow{
+		Name:       definitions.Collaboration.Name,
+		Properties: newProperties(definitions.Collaboration.Properties),
+	}
+	references := newReferenceCollector()
+	references.add(flow.Properties)
+
+	for _, participantXML := range definitions.Collaboration.Participants {
+		participant := &Participant{
+			Id:         participantXML.Id,
+			Name:       participantXML.Name,
+			Type:       participantXML.Type,
+			Properties: newProperties(participantXML.Properties),
+		}
+		references.add(participant.Properties)
+		flow.Participants = append(flow.Participants, participant)
+	}
+
+	for _, messageFlowXML := range definitions.Collaboration.MessageFlows {
+		properties := newProperties(messageFlowXML.Properties)
+		channel := &Channel{
+			Id:                messageFlowXML.Id,
+			Name:              messageFlowXML.Name,
+			Source:            messageFlowXML.SourceRef,
+			Target:            messageFlowXML.TargetRef,
+			ComponentType:     properties["ComponentType"],
+			Direction:         properties["direction"],
+			TransportProtocol: properties["TransportProtocol"],
+			MessageProtocol:   properties["MessageProtocol"],
+			Properties:        properties,
+		}
+		if channel.Direction == "" {
+			channel.Direction = properties["Direction"]
+		}
+		references.add(properties)
+		flow.Channels = append(flow.Channels, channel)
+	}
+
+	for _, processXML := range definitions.Processes {
+		process := &Process{
+			Id:         processXML.Id,
+			Name:       processXML.Name,
+			Properties: newProperties(processXML.Properties),
+		}
+		references.add(process.Properties)
+		process.Steps = newSteps(processXML.Elements, references)
+		flow.Processes = append(flow.Processes, process)
+
+		for _, step := range process.Steps {
+			if collection := step.Properties["scriptBundleId"]; collection != "" {
+				flow.ScriptCollections = appendUnique(flow.ScriptCollections, collection)
+			}
+		}
+	}
+
+	sort.Strings(flow.ScriptCollections)
+	flow.ParameterReferences = references.keys()
+
+	return flow, nil
+}
+
+//Sender participants
+func (flow *IntegrationFlow) Senders() []*Participant {
+	return flow.participantsByType("EndpointSender")
+}
+
+//Receiver participants. CPI writes type with typo, both variants are accepted
+func (flow *IntegrationFlow) Receivers() []*Participant {
+	return append(flow.participantsByType("EndpointRecevier"), flow.participantsByType("EndpointReceiver")...)
+}
+
+func (flow *IntegrationFlow) participantsByType(participantType string) []*Participant {
+	var participants []*Participant
+	for _, participant := range flow.Participants {
+		if participant.Type == participantType {
+			participants = append(participants, participant)
+		}
+	}
+	return participants
+}
+
+//Address of adapter: URL of receiver, or path of sender endpoint
+func (channel *Channel) Endpoint() string {
+	for _, key := range []string{"address", "Address", "httpAddressWithoutQuery", "urlPath", "host", "Host"} {
+		if value := channel.Properties[key]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+//Steps of process, steps of subprocesses are added after subprocess itself. Only elements with ID are steps,
+//children like incoming, outgoing or event definitions are skipped
+func newSteps(elements []elementXML, references *referenceCollector) []*Step {
+	var steps []*Step
+	for _, element := range elements {
+		if element.Id == "" || element.XMLName.Local == "sequenceFlow" {
+			continue
+		}
+		properties := newProperties(element.Properties)
+		references.add(properties)
+		steps = append(steps, &Step{
+			Id:           element.Id,
+			Name:         element.Name,
+			Kind:         element.XMLName.Local,
+			ActivityType: properties["activityType"],
+			Properties:   properties,
+		})
+		steps = append(steps, newSteps(element.Elements, references)...)
+	}
+	return steps
+}
+
+func newProperties(propertiesXML []propertyXML) map[string]string {
+	properties := make(map[string]string)
+	for _, property := range propertiesXML {
+		properties[property.Key] = property.Value
+	}
+	return properties
+}
+
+//Collects keys of externalized parameters from property values
+type referenceCollector struct {
+	found map[string]bool
+}
+
+func newReferenceCollector() *referenceCollector {
+	return &referenceCollector{found: make(map[string]bool)}
+}
+
+func (collector *referenceCollector) add(properties map[string]string) {
+	for _, value := range properties {
+		for _, match := range parameterReferencePattern.FindAllStringSubmatch(value, -1) {
+			collector.found[match[1]] = true
+		}
+	}
+}
+
+func (collector *referenceCollector) keys() []string {
+	var keys []string
+	for key := range collector.found {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
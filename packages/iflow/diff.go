@@ -0,0 +1,538 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package iflow
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Trifolium-project/landscaper/packages/util"
+)
+
+//Kind of difference. Added means, that element exists only in target, removed - only in source
+const (
+	Added   = "added"
+	Removed = "removed"
+	Changed = "changed"
+)
+
+//Lines of unchanged content around each change in unified diff
+const diffContextLines = 3
+
+//Content difference between two artifacts
+type Diff struct {
+	Manifest   []*PropertyChange `json:"manifest,omitempty"`
+	Parameters []*PropertyChange `json:"parameters,omitempty"`
+	Steps      []*ElementChange  `json:"steps,omitempty"`
+	Adapters   []*ElementChange  `json:"adapters,omitempty"`
+	Files      []*FileChange     `json:"files,omitempty"`
+}
+
+type PropertyChange struct {
+	Key    string `json:"key"`
+	Change string `json:"change"`
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+//Changed step or adapter of integration flow, matched by ID
+type ElementChange struct {
+	Flow       string            `json:"flow"`
+	Id         string            `json:"id"`
+	Name       string            `json:"name"`
+	Change     string            `json:"change"`
+	Properties []*PropertyChange `json:"properties,omitempty"`
+}
+
+//Changed file. Text files are compared line by line, diff is in unified format
+type FileChange struct {
+	Path   string `json:"path"`
+	Change string `json:"change"`
+	Diff   string `json:"diff,omitempty"`
+}
+
+//Files, which are compared as part of structured model, are not compared as files
+var modelFiles = []string{manifestPath, parametersPath, propdefPath}
+
+//Diff is empty
+func (diff *Diff) IsEmpty() bool {
+	return len(diff.Manifest) == 0 && len(diff.Parameters) == 0 && len(diff.Steps) == 0 &&
+		len(diff.Adapters) == 0 && len(diff.Files) == 0
+}
+
+//Compare content of two artifacts
+func Compare(source *Artifact, target *Artifact) *Diff {
+	diff := &Diff{}
+
+	diff.Manifest = compareProperties(manifestAttributes(source), manifestAttributes(target))
+	diff.Parameters = compareProperties(parameterValues(source), parameterValues(target))
+
+	//Integration flow file is named after artifact, so flows are matched by directory
+	sourceFlows := flowsByDirectory(source)
+	targetFlows := flowsByDirectory(target)
+	for _, directory := range unionKeys(flowDirectories(sourceFlows), flowDirectories(targetFlows)) {
+		sourceFlow, targetFlow := sourceFlows[directory], targetFlows[directory]
+		flowName := path.Join(directory, "*.iflw")
+		diff.Steps = append(diff.Steps, compareElements(flowName, stepElements(sourceFlow), stepElements(targetFlow))...)
+		diff.Adapters = append(diff.Adapters, compareElements(flowName, channelElements(sourceFlow), channelElements(targetFlow))...)
+	}
+
+	sourceFiles := resourceFiles(source)
+	targetFiles := resourceFiles(target)
+	for _, name := range unionKeys(sortedFileNames(sourceFiles), sortedFileNames(targetFiles)) {
+		sourceContent, inSource := sourceFiles[name]
+		targetContent, inTarget := targetFiles[name]
+		switch {
+		case !inTarget:
+			diff.Files = append(diff.Files, &FileChange{Path: name, Change: Removed})
+		case !inSource:
+			diff.Files = append(diff.Files, &FileChange{Path: name, Change: Added})
+		case !equalFiles(name, sourceContent, targetContent):
+			change := &FileChange{Path: name, Change: Changed}
+			if isText(sourceContent) && isText(targetContent) {
+				change.Diff = UnifiedDiff(sourceContent, targetContent, "a/"+name, "b/"+name)
+			}
+			diff.Files = append(diff.Files, change)
+		}
+	}
+
+	return diff
+}
+
+//Unified diff of two texts, based on longest common subsequence of lines
+func UnifiedDiff(source []byte, target []byte, sourceName string, targetName string) string {
+	sourceLines := splitLines(source)
+	targetLines := splitLines(target)
+	operations := diffLines(sourceLines, targetLines)
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "--- %s\n+++ %s\n", sourceName, targetName)
+
+	for start := 0; start < len(operations); {
+		//Find first change, hunk starts with context before it
+		for start < len(operations) && operations[start].kind == ' ' {
+			start++
+		}
+		if start == len(operations) {
+			break
+		}
+		first := start - diffContextLines
+		if first < 0 {
+			first = 0
+		}
+
+		//Extend hunk, while next change is close enough to share context
+		end := start
+		for index := start; index < len(operations); index++ {
+			if operations[index].kind != ' ' {
+				end = index
+				continue
+			}
+			if index-end > 2*diffContextLines {
+				break
+			}
+		}
+		last := end + diffContextLines
+		if last >= len(operations) {
+			last = len(operations) - 1
+		}
+
+		writeHunk(&builder, operations[first:last+1])
+		start = last + 1
+	}
+
+	return builder.String()
+}
+
+type lineOperation struct {
+	//' ' - unchanged, '-' - only in source, '+' - only in target
+	kind byte
+	line string
+	//Line numbers before operation
+	sourceLine int
+	targetLine int
+}
+
+//Line diff in linear memory: common prefix and suffix are skipped, the rest is split recursively by Hirschberg's algorithm,
+//so that large resources, e.g. WSDL or XSD files, can be compared
+func diffLines(source []string, target []string) []lineOperation {
+	differ := &lineDiffer{source: source, target: target}
+
+	//Lines are compared by number
+	numbers := make(map[string]int)
+	differ.sourceNumbers = lineNumbers(source, numbers)
+	differ.targetNumbers = lineNumbers(target, numbers)
+
+	differ.diff(0, len(source), 0, len(target))
+	return differ.operations
+}
+
+type lineDiffer struct {
+	source        []string
+	target        []string
+	sourceNumbers []int
+	targetNumbers []int
+	operations    []lineOperation
+}
+
+func lineNumbers(lines []string, numbers map[string]int) []int {
+	result := make([]int, len(lines))
+	for index, line := range lines {
+		number, ok := numbers[line]
+		if !ok {
+			number = len(numbers)
+			numbers[line] = number
+		}
+		result[index] = number
+	}
+	return result
+}
+
+//Append operations, which transform source[sourceStart:sourceEnd] to target[targetStart:targetEnd]
+func (differ *lineDiffer) diff(sourceStart int, sourceEnd int, targetStart int, targetEnd int) {
+	source, target := differ.sourceNumbers, differ.targetNumbers
+
+	//Common prefix
+	for sourceStart < sourceEnd && targetStart < targetEnd && source[sourceStart] == target[targetStart] {
+		differ.add(' ', sourceStart, targetStart)
+		sourceStart++
+		targetStart++
+	}
+	//Common suffix is added after changes
+	suffix := 0
+	for sourceStart < sourceEnd-suffix && targetStart < targetEnd-suffix && source[sourceEnd-suffix-1] == target[targetEnd-suffix-1] {
+		suffix++
+	}
+	sourceEnd -= suffix
+	targetEnd -= suffix
+
+	switch {
+	case sourceStart == sourceEnd:
+		for j := targetStart; j < targetEnd; j++ {
+			differ.add('+', sourceStart, j)
+		}
+	case targetStart == targetEnd:
+		for i := sourceStart; i < sourceEnd; i++ {
+			differ.add('-', i, targetStart)
+		}
+	case sourceEnd-sourceStart == 1:
+		//Line is either found in target, or replaced. Prefix and suffix are already skipped, so line is not first or last in target
+		found := -1
+		for j := targetStart; j < targetEnd; j++ {
+			if target[j] == source[sourceStart] {
+				found = j
+				break
+			}
+		}
+		if found < 0 {
+			differ.add('-', sourceStart, targetStart)
+			for j := targetStart; j < targetEnd; j++ {
+				differ.add('+', sourceStart+1, j)
+			}
+			break
+		}
+		for j := targetStart; j < found; j++ {
+			differ.add('+', sourceStart, j)
+		}
+		differ.add(' ', sourceStart, found)
+		for j := found + 1; j < targetEnd; j++ {
+			differ.add('+', sourceStart+1, j)
+		}
+	default:
+		//Split source in the middle, and target, where sum of common subsequences of both halves is maximal
+		middle := (sourceStart + sourceEnd) / 2
+		forward := forwardCommonLengths(source[sourceStart:middle], target[targetStart:targetEnd])
+		backward := backwardCommonLengths(source[middle:sourceEnd], target[targetStart:targetEnd])
+		split := 0
+		for k := range forward {
+			if forward[k]+backward[k] > forward[split]+backward[split] {
+				split = k
+			}
+		}
+		differ.diff(sourceStart, middle, targetStart, targetStart+split)
+		differ.diff(middle, sourceEnd, targetStart+split, targetEnd)
+	}
+
+	for k := 0; k < suffix; k++ {
+		differ.add(' ', sourceEnd+k, targetEnd+k)
+	}
+}
+
+func (differ *lineDiffer) add(kind byte, sourceLine int, targetLine int) {
+	line := ""
+	if kind == '+' {
+		line = differ.target[targetLine]
+	} else {
+		line = differ.source[sourceLine]
+	}
+	differ.operations = append(differ.operations, lineOperation{kind, line, sourceLine, targetLine})
+}
+
+//Length of common subsequence of source and target[:k] for every k
+func forwardCommonLengths(source []int, target []int) []int {
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for i := range source {
+		for j := range target {
+			if source[i] == target[j] {
+				current[j+1] = previous[j] + 1
+			} else if previous[j+1] >= current[j] {
+				current[j+1] = previous[j+1]
+			} else {
+				current[j+1] = current[j]
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous
+}
+
+//Length of common subsequence of source and target[k:] for every k
+func backwardCommonLengths(source []int, target []int) []int {
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+	for i := len(source) - 1; i >= 0; i-- {
+		for j := len(target) - 1; j >= 0; j-- {
+			if source[i] == target[j] {
+				current[j] = previous[j+1] + 1
+			} else if previous[j] >= current[j+1] {
+				current[j] = previous[j]
+			} else {
+				current[j] = current[j+1]
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous
+}
+
+func writeHunk(builder *strings.Builder, operations []lineOperation) {
+	sourceCount, targetCount := 0, 0
+	for _, operation := range operations {
+		if operation.kind != '+' {
+			sourceCount++
+		}
+		if operation.kind != '-' {
+			targetCount++
+		}
+	}
+
+	//Empty range starts at line before it
+	sourceStart, targetStart := operations[0].sourceLine, operations[0].targetLine
+	if sourceCount > 0 {
+		sourceStart++
+	}
+	if targetCount > 0 {
+		targetStart++
+	}
+
+	fmt.Fprintf(builder, "@@ -%d,%d +%d,%d @@\n", sourceStart, sourceCount, targetStart, targetCount)
+	for _, operation := range operations {
+		fmt.Fprintf(builder, "%c%s\n", operation.kind, operation.line)
+	}
+}
+
+func splitLines(content []byte) []string {
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+func compareProperties(source map[string]string, target map[string]string) []*PropertyChange {
+	var changes []*PropertyChange
+	for _, key := range unionKeys(sortedKeys(source), sortedKeys(target)) {
+		sourceValue, inSource := source[key]
+		targetValue, inTarget := target[key]
+		switch {
+		case !inTarget:
+			changes = append(changes, &PropertyChange{Key: key, Change: Removed, Source: sourceValue})
+		case !inSource:
+			changes = append(changes, &PropertyChange{Key: key, Change: Added, Target: targetValue})
+		case sourceValue != targetValue:
+			changes = append(changes, &PropertyChange{Key: key, Change: Changed, Source: sourceValue, Target: targetValue})
+		}
+	}
+	return changes
+}
+
+//Step or adapter, reduced to properties for comparison
+type element struct {
+	id         string
+	name       string
+	properties map[string]string
+}
+
+func compareElements(flow string, source []*element, target []*element) []*ElementChange {
+	sourceById := make(map[string]*element)
+	for _, element := range source {
+		sourceById[element.id] = element
+	}
+	targetById := make(map[string]*element)
+	for _, element := range target {
+		targetById[element.id] = element
+	}
+
+	var changes []*ElementChange
+	//Order of source is kept, added elements are reported after it
+	for _, sourceElement := range source {
+		targetElement, ok := targetById[sourceElement.id]
+		if !ok {
+			changes = append(changes, &ElementChange{Flow: flow, Id: sourceElement.id, Name: sourceElement.name, Change: Removed})
+			continue
+		}
+		properties := compareProperties(sourceElement.properties, targetElement.properties)
+		if len(properties) > 0 {
+			changes = append(changes, &ElementChange{Flow: flow, Id: sourceElement.id, Name: targetElement.name, Change: Changed, Properties: properties})
+		}
+	}
+	for _, targetElement := range target {
+		if _, ok := sourceById[targetElement.id]; !ok {
+			changes = append(changes, &ElementChange{Flow: flow, Id: targetElement.id, Name: targetElement.name, Change: Added})
+		}
+	}
+	return changes
+}
+
+func stepElements(flow *IntegrationFlow) []*element {
+	var elements []*element
+	if flow == nil {
+		return elements
+	}
+	for _, process := range flow.Processes {
+		for _, step := range process.Steps {
+			properties := copyProperties(step.Properties)
+			//Renamed step is reported as changed step
+			properties["name"] = step.Name
+			elements = append(elements, &element{id: step.Id, name: step.Name, properties: properties})
+		}
+	}
+	return elements
+}
+
+func channelElements(flow *IntegrationFlow) []*element {
+	var elements []*element
+	if flow == nil {
+		return elements
+	}
+	for _, channel := range flow.Channels {
+		properties := copyProperties(channel.Properties)
+		properties["name"] = channel.Name
+		elements = append(elements, &element{id: channel.Id, name: channel.Name, properties: properties})
+	}
+	return elements
+}
+
+func flowsByDirectory(artifact *Artifact) map[string]*IntegrationFlow {
+	flows := make(map[string]*IntegrationFlow)
+	for _, flow := range artifact.Flows {
+		flows[path.Dir(flow.Path)] = flow
+	}
+	return flows
+}
+
+func manifestAttributes(artifact *Artifact) map[string]string {
+	if artifact.Manifest == nil {
+		return map[string]string{}
+	}
+	return artifact.Manifest.Attributes
+}
+
+func parameterValues(artifact *Artifact) map[string]string {
+	values := make(map[string]string)
+	for _, parameter := range artifact.Parameters {
+		values[parameter.Key] = parameter.Value
+	}
+	return values
+}
+
+//Files, which are not part of structured model. Integration flow files are compared as steps and adapters
+func resourceFiles(artifact *Artifact) map[string][]byte {
+	files := make(map[string][]byte)
+	for name, content := range artifact.Files {
+		if strings.HasSuffix(name, "/") || path.Ext(name) == ".iflw" {
+			continue
+		}
+		if !util.Contains(modelFiles, name) {
+			files[name] = content
+		}
+	}
+	return files
+}
+
+//Properties files contain timestamp in comment, so they are compared by values
+func equalFiles(name string, source []byte, target []byte) bool {
+	if path.Ext(name) != ".prop" {
+		return bytes.Equal(source, target)
+	}
+	sourceKeys, sourceValues := ParseProperties(source)
+	targetKeys, targetValues := ParseProperties(target)
+	if len(sourceKeys) != len(targetKeys) {
+		return false
+	}
+	for _, key := range sourceKeys {
+		if value, ok := targetValues[key]; !ok || value != sourceValues[key] {
+			return false
+		}
+	}
+	return true
+}
+
+func isText(content []byte) bool {
+	return utf8.Valid(content) && bytes.IndexByte(content, 0) == -1
+}
+
+func copyProperties(properties map[string]string) map[string]string {
+	copied := make(map[string]string)
+	for key, value := range properties {
+		copied[key] = value
+	}
+	return copied
+}
+
+//Sorted keys, which exist in any of lists
+func unionKeys(source []string, target []string) []string {
+	keys := append([]string{}, source...)
+	for _, key := range target {
+		if !util.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedKeys(properties map[string]string) []string {
+	var keys []string
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func flowDirectories(flows map[string]*IntegrationFlow) []string {
+	var directories []string
+	for directory := range flows {
+		directories = append(directories, directory)
+	}
+	return directories
+}
@@ -0,0 +1,178 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//Parser of integration flow content, downloaded from CPI as zip archive
+package iflow
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/Trifolium-project/landscaper/packages/util"
+)
+
+const (
+	manifestPath   = "META-INF/MANIFEST.MF"
+	parametersPath = "src/main/resources/parameters.prop"
+	propdefPath    = "src/main/resources/parameters.propdef"
+)
+
+//Content of integration flow artifact
+type Artifact struct {
+	Manifest   *Manifest
+	Flows      []*IntegrationFlow
+	Parameters []*Parameter
+	Scripts    []*Resource
+	Mappings   []*Resource
+	Schemas    []*Resource
+	//All files of archive, key is path in archive
+	Files map[string][]byte
+}
+
+//File of artifact, e.g. script or XSD
+type Resource struct {
+	Path    string
+	Name    string
+	Content []byte
+}
+
+//Parse artifact from base64 encoded content, as it is returned by CPI API
+func FromArtifactContent(content string) (*Artifact, error) {
+	decoded, err := base64.StdEncoding.DecodeString(content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode artifact content: %s", err)
+	}
+	return Parse(decoded)
+}
+
+//Parse artifact from zip archive
+func Parse(content []byte) (*Artifact, error) {
+	files, err := util.ReadZipEntries(content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read artifact archive: %s", err)
+	}
+
+	artifact := &Artifact{Files: files}
+
+	if manifest, ok := files[manifestPath]; ok {
+		artifact.Manifest = ParseManifest(manifest)
+	}
+
+	artifact.Parameters, err = parseParameters(files[parametersPath], files[propdefPath])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range sortedFileNames(files) {
+		resource := &Resource{Path: name, Name: path.Base(name), Content: files[name]}
+
+		switch extension := strings.ToLower(path.Ext(name)); {
+		case extension == ".iflw":
+			flow, err := ParseIntegrationFlow(files[name])
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse %s: %s", name, err)
+			}
+			flow.Path = name
+			artifact.Flows = append(artifact.Flows, flow)
+		case strings.Contains(name, "/script/") || extension == ".groovy" || extension == ".gsh":
+			artifact.Scripts = append(artifact.Scripts, resource)
+		case strings.Contains(name, "/mapping/") || extension == ".mmap" || extension == ".xsl" || extension == ".xslt" || extension == ".opmap":
+			artifact.Mappings = append(artifact.Mappings, resource)
+		case extension == ".xsd" || extension == ".wsdl":
+			artifact.Schemas = append(artifact.Schemas, resource)
+		}
+	}
+
+	return artifact, nil
+}
+
+//Get externalized parameter by key
+func (artifact *Artifact) Parameter(key string) *Parameter {
+	for _, parameter := range artifact.Parameters {
+		if parameter.Key == key {
+			return parameter
+		}
+	}
+	return nil
+}
+
+//Sender participants of all integration flows
+func (artifact *Artifact) Senders() []*Participant {
+	var senders []*Participant
+	for _, flow := range artifact.Flows {
+		senders = append(senders, flow.Senders()...)
+	}
+	return senders
+}
+
+//Receiver participants of all integration flows
+func (artifact *Artifact) Receivers() []*Participant {
+	var receivers []*Participant
+	for _, flow := range artifact.Flows {
+		receivers = append(receivers, flow.Receivers()...)
+	}
+	return receivers
+}
+
+//Adapters of all integration flows
+func (artifact *Artifact) Adapters() []*Channel {
+	var adapters []*Channel
+	for _, flow := range artifact.Flows {
+		adapters = append(adapters, flow.Channels...)
+	}
+	return adapters
+}
+
+//IDs of script collections, referenced by integration flows
+func (artifact *Artifact) ScriptCollections() []string {
+	var collections []string
+	for _, flow := range artifact.Flows {
+		collections = appendUnique(collections, flow.ScriptCollections...)
+	}
+	sort.Strings(collections)
+	return collections
+}
+
+//Keys of externalized parameters, referenced as {{key}} in integration flows
+func (artifact *Artifact) ParameterReferences() []string {
+	var references []string
+	for _, flow := range artifact.Flows {
+		references = appendUnique(references, flow.ParameterReferences...)
+	}
+	sort.Strings(references)
+	return references
+}
+
+func sortedFileNames(files map[string][]byte) []string {
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func appendUnique(values []string, additional ...string) []string {
+	for _, value := range additional {
+		if !util.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
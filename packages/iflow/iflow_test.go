@@ -0,0 +1,491 @@
+package iflow
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testManifest = "Manifest-Version: 1.0\r\n" +
+	"Bundle-SymbolicName: Replicate_Orders; singleton:=true\r\n" +
+	"Bundle-Name: Replicate Orders\r\n" +
+	"Bundle-Version: 1.0.3\r\n" +
+	"SAP-BundleType: IntegrationFlow\r\n" +
+	"Import-Package: com.sap.esb.application.services.cxf.interceptor,com.sap\r\n" +
+	" .esb.security\r\n"
+
+const testIntegrationFlow = `<?xml version="1.0" encoding="UTF-8"?>
+<bpmn2:definitions xmlns:bpmn2="http://www.omg.org/spec/BPMN/20100524/MODEL" xmlns:ifl="http:///com.sap.ifl.model/Ifl.xsd" id="Definitions_1">
+  <bpmn2:collaboration id="Collaboration_1" name="Default Collaboration">
+    <bpmn2:extensionElements>
+      <ifl:property><key>namespaceMapping</key><value/></ifl:property>
+    </bpmn2:extensionElements>
+    <bpmn2:participant id="Participant_1" ifl:type="EndpointSender" name="ERP">
+      <bpmn2:extensionElements>
+        <ifl:property><key>ifl:type</key><value>EndpointSender</value></ifl:property>
+      </bpmn2:extensionElements>
+    </bpmn2:participant>
+    <bpmn2:participant id="Participant_2" ifl:type="EndpointRecevier" name="Backend"/>
+    <bpmn2:participant id="Participant_Process_1" ifl:type="IntegrationProcess" name="Integration Process" processRef="Process_1"/>
+    <bpmn2:messageFlow id="MessageFlow_1" name="HTTPS" sourceRef="Participant_1" targetRef="StartEvent_1">
+      <bpmn2:extensionElements>
+        <ifl:property><key>ComponentType</key><value>HTTPS</value></ifl:property>
+        <ifl:property><key>Direction</key><value>Sender</value></ifl:property>
+        <ifl:property><key>urlPath</key><value>/orders</value></ifl:property>
+        <ifl:property><key>TransportProtocol</key><value>HTTPS</value></ifl:property>
+      </bpmn2:extensionElements>
+    </bpmn2:messageFlow>
+    <bpmn2:messageFlow id="MessageFlow_2" name="HTTP" sourceRef="ServiceTask_1" targetRef="Participant_2">
+      <bpmn2:extensionElements>
+        <ifl:property><key>ComponentType</key><value>HTTP</value></ifl:property>
+        <ifl:property><key>direction</key><value>Receiver</value></ifl:property>
+        <ifl:property><key>httpAddressWithoutQuery</key><value>https://{{Host}}/{{Endpoint}}</value></ifl:property>
+      </bpmn2:extensionElements>
+    </bpmn2:messageFlow>
+  </bpmn2:collaboration>
+  <bpmn2:process id="Process_1" name="Integration Process">
+    <bpmn2:extensionElements>
+      <ifl:property><key>transactionTimeout</key><value>30</value></ifl:property>
+    </bpmn2:extensionElements>
+    <bpmn2:startEvent id="StartEvent_1" name="Start">
+      <bpmn2:outgoing>SequenceFlow_1</bpmn2:outgoing>
+      <bpmn2:messageEventDefinition/>
+    </bpmn2:startEvent>
+    <bpmn2:callActivity id="CallActivity_1" name="Prepare">
+      <bpmn2:extensionElements>
+        <ifl:property><key>activityType</key><value>Script</value></ifl:property>
+        <ifl:property><key>script</key><value>prepare.groovy</value></ifl:property>
+        <ifl:property><key>scriptBundleId</key><value>CommonScripts</value></ifl:property>
+      </bpmn2:extensionElements>
+      <bpmn2:incoming>SequenceFlow_1</bpmn2:incoming>
+      <bpmn2:outgoing>SequenceFlow_2</bpmn2:outgoing>
+    </bpmn2:callActivity>
+    <bpmn2:subProcess id="SubProcess_1" name="Exception Subprocess">
+      <bpmn2:callActivity id="CallActivity_2" name="Log error">
+        <bpmn2:extensionElements>
+          <ifl:property><key>activityType</key><value>Script</value></ifl:property>
+          <ifl:property><key>scriptBundleId</key><value>Logging</value></ifl:property>
+        </bpmn2:extensionElements>
+      </bpmn2:callActivity>
+    </bpmn2:subProcess>
+    <bpmn2:serviceTask id="ServiceTask_1" name="Send">
+      <bpmn2:incoming>SequenceFlow_2</bpmn2:incoming>
+    </bpmn2:serviceTask>
+    <bpmn2:sequenceFlow id="SequenceFlow_1" sourceRef="StartEvent_1" targetRef="CallActivity_1"/>
+    <bpmn2:sequenceFlow id="SequenceFlow_2" sourceRef="CallActivity_1" targetRef="ServiceTask_1"/>
+  </bpmn2:process>
+</bpmn2:definitions>
+`
+
+const testParameters = `#Store parameters
+#Mon Jan 01 00:00:00 UTC 2022
+Host=backend.example.com
+Endpoint=api/orders\:v1
+Timeout = 60
+`
+
+const testPropdef = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<parameters>
+  <parameter><name>Host</name><type>xsd:string</type><isRequired>true</isRequired><description>Backend host</description></parameter>
+  <parameter><name>Timeout</name><type>xsd:integer</type><isRequired>false</isRequired></parameter>
+  <parameter><name>Retries</name><type>xsd:integer</type><isRequired>false</isRequired></parameter>
+</parameters>
+`
+
+func newTestArchive(t *testing.T, files map[string]string) []byte {
+	buffer := new(bytes.Buffer)
+	archive := zip.NewWriter(buffer)
+	for name, content := range files {
+		writer, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func TestParse(t *testing.T) {
+	content := newTestArchive(t, map[string]string{
+		"META-INF/MANIFEST.MF": testManifest,
+		"src/main/resources/scenarioflows/integrationflow/Replicate_Orders.iflw": testIntegrationFlow,
+		"src/main/resources/parameters.prop":                                     testParameters,
+		"src/main/resources/parameters.propdef":                                  testPropdef,
+		"src/main/resources/script/prepare.groovy":                               "def Message processData(Message message) { message }",
+		"src/main/resources/mapping/Orders.mmap":                                 "<mapping/>",
+		"src/main/resources/xsd/Orders.xsd":                                      "<schema/>",
+		"src/main/resources/wsdl/Orders.wsdl":                                    "<definitions/>",
+	})
+
+	artifact, err := Parse(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := artifact.Manifest
+	if manifest.SymbolicName != "Replicate_Orders" || manifest.Version != "1.0.3" || manifest.BundleType != "IntegrationFlow" {
+		t.Error("Unexpected manifest ", manifest)
+	}
+	if manifest.Attributes["Import-Package"] != "com.sap.esb.application.services.cxf.interceptor,com.sap.esb.security" {
+		t.Error("Expected continuation line to be joined, got ", manifest.Attributes["Import-Package"])
+	}
+
+	if len(artifact.Flows) != 1 {
+		t.Fatalf("Expected 1 integration flow, got %d", len(artifact.Flows))
+	}
+	if senders := artifact.Senders(); len(senders) != 1 || senders[0].Name != "ERP" {
+		t.Error("Unexpected senders ", senders)
+	}
+	if receivers := artifact.Receivers(); len(receivers) != 1 || receivers[0].Name != "Backend" {
+		t.Error("Unexpected receivers ", receivers)
+	}
+
+	adapters := artifact.Adapters()
+	if len(adapters) != 2 {
+		t.Fatalf("Expected 2 adapters, got %d", len(adapters))
+	}
+	if adapters[0].ComponentType != "HTTPS" || adapters[0].Direction != "Sender" || adapters[0].Endpoint() != "/orders" {
+		t.Error("Unexpected sender adapter ", adapters[0])
+	}
+	if adapters[1].Direction != "Receiver" || adapters[1].Endpoint() != "https://{{Host}}/{{Endpoint}}" {
+		t.Error("Unexpected receiver adapter ", adapters[1])
+	}
+
+	steps := artifact.Flows[0].Processes[0].Steps
+	if len(steps) != 5 || steps[2].Kind != "subProcess" || steps[3].Name != "Log error" {
+		t.Error("Unexpected steps ", steps)
+	}
+	for _, step := range steps {
+		if step.Id == "" {
+			t.Errorf("Unexpected step without ID of kind %s", step.Kind)
+		}
+	}
+
+	if collections := artifact.ScriptCollections(); len(collections) != 2 || collections[0] != "CommonScripts" {
+		t.Error("Unexpected script collections ", collections)
+	}
+	if references := artifact.ParameterReferences(); len(references) != 2 || references[0] != "Endpoint" || references[1] != "Host" {
+		t.Error("Unexpected parameter references ", references)
+	}
+
+	expected := []Parameter{
+		{Key: "Host", Value: "backend.example.com", Type: "xsd:string", Required: true, Description: "Backend host", Defined: true},
+		{Key: "Endpoint", Value: "api/orders:v1", Type: "xsd:string"},
+		{Key: "Timeout", Value: "60", Type: "xsd:integer", Defined: true},
+		{Key: "Retries", Type: "xsd:integer", Defined: true},
+	}
+	if len(artifact.Parameters) != len(expected) {
+		t.Fatalf("Expected %d parameters, got %d", len(expected), len(artifact.Parameters))
+	}
+	for index, parameter := range artifact.Parameters {
+		if *parameter != expected[index] {
+			t.Errorf("Expected parameter %v, got %v", expected[index], *parameter)
+		}
+	}
+
+	if len(artifact.Scripts) != 1 || len(artifact.Mappings) != 1 || len(artifact.Schemas) != 2 {
+		t.Errorf("Unexpected resources: %d scripts, %d mappings, %d schemas", len(artifact.Scripts), len(artifact.Mappings), len(artifact.Schemas))
+	}
+}
+
+func TestParseProperties(t *testing.T) {
+	keys, values := ParseProperties([]byte("a=1\nb\\ key : long \\\n    value\nc\\u0041=\\u0042\n! comment\n"))
+
+	if len(keys) != 3 || keys[1] != "b key" || keys[2] != "cA" {
+		t.Fatal("Unexpected keys ", keys)
+	}
+	if values["b key"] != "long value" || values["cA"] != "B" {
+		t.Error("Unexpected values ", values)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	source, err := Parse(newTestArchive(t, map[string]string{
+		"META-INF/MANIFEST.MF": testManifest,
+		"src/main/resources/scenarioflows/integrationflow/Replicate_Orders.iflw": testIntegrationFlow,
+		"src/main/resources/parameters.prop":                                     testParameters,
+		"src/main/resources/script/prepare.groovy":                               "import com.sap.gateway.ip.core.customdev.util.Message\n\ndef Message processData(Message message) {\n    return message\n}\n",
+		"src/main/resources/xsd/Orders.xsd":                                      "<schema/>",
+		"metainfo.prop":                                                          "#Store metainfo properties\n#Mon Jan 01 00:00:00 UTC 2022\ndescription=Orders\n",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//Target is same artifact in another environment, with changed adapter, script and parameter
+	targetFlow := strings.Replace(testIntegrationFlow, "<value>/orders</value>", "<value>/orders/v2</value>", 1)
+	targetFlow = strings.Replace(targetFlow, `name="Send"`, `name="Send order"`, 1)
+	target, err := Parse(newTestArchive(t, map[string]string{
+		"META-INF/MANIFEST.MF": strings.Replace(testManifest, "Replicate_Orders;", "Replicate_Orders_QA;", 1),
+		"src/main/resources/scenarioflows/integrationflow/Replicate_Orders_QA.iflw": targetFlow,
+		"src/main/resources/parameters.prop":                                        strings.Replace(testParameters, "Timeout = 60", "Timeout = 120", 1),
+		"src/main/resources/script/prepare.groovy":                                  "import com.sap.gateway.ip.core.customdev.util.Message\n\ndef Message processData(Message message) {\n    message.setHeader(\"Source\", \"QA\")\n    return message\n}\n",
+		"src/main/resources/script/log.groovy":                                      "",
+		"metainfo.prop":                                                             "#Store metainfo properties\n#Tue Jan 02 00:00:00 UTC 2022\ndescription=Orders\n",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff := Compare(source, target)
+
+	if len(diff.Manifest) != 1 || diff.Manifest[0].Key != "Bundle-SymbolicName" || diff.Manifest[0].Change != Changed {
+		t.Error("Unexpected manifest changes ", diff.Manifest)
+	}
+	if len(diff.Parameters) != 1 || diff.Parameters[0].Key != "Timeout" || diff.Parameters[0].Source != "60" || diff.Parameters[0].Target != "120" {
+		t.Error("Unexpected parameter changes ", diff.Parameters)
+	}
+	if len(diff.Adapters) != 1 || diff.Adapters[0].Id != "MessageFlow_1" || len(diff.Adapters[0].Properties) != 1 || diff.Adapters[0].Properties[0].Key != "urlPath" {
+		t.Error("Unexpected adapter changes ", diff.Adapters)
+	}
+	if len(diff.Steps) != 1 || diff.Steps[0].Id != "ServiceTask_1" || diff.Steps[0].Name != "Send order" {
+		t.Error("Unexpected step changes ", diff.Steps)
+	}
+
+	//Properties file with changed timestamp only is not reported
+	expectedFiles := []FileChange{
+		{Path: "src/main/resources/script/log.groovy", Change: Added},
+		{Path: "src/main/resources/script/prepare.groovy", Change: Changed},
+		{Path: "src/main/resources/xsd/Orders.xsd", Change: Removed},
+	}
+	if len(diff.Files) != len(expectedFiles) {
+		t.Fatal("Unexpected file changes ", diff.Files)
+	}
+	for index, change := range diff.Files {
+		if change.Path != expectedFiles[index].Path || change.Change != expectedFiles[index].Change {
+			t.Errorf("Expected file change %v, got %v", expectedFiles[index], *change)
+		}
+	}
+	if !strings.Contains(diff.Files[1].Diff, "+    message.setHeader(\"Source\", \"QA\")\n") {
+		t.Error("Expected script diff, got ", diff.Files[1].Diff)
+	}
+
+	if !Compare(source, source).IsEmpty() {
+		t.Error("Expected no differences for same artifact")
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	source := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n"
+	target := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n"
+
+	expected := `--- a/file
++++ b/file
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -13,3 +13,4 @@
+ 13
+ 14
+ 15
++16
+`
+	if diff := UnifiedDiff([]byte(source), []byte(target), "a/file", "b/file"); diff != expected {
+		t.Errorf("Expected diff\n%s\ngot\n%s", expected, diff)
+	}
+}
+
+func TestUnifiedDiffLargeFile(t *testing.T) {
+	var source, target strings.Builder
+	for i := 0; i < 20000; i++ {
+		fmt.Fprintf(&source, "<xsd:element name=\"field%d\"/>\n", i)
+		if i%1000 == 500 {
+			fmt.Fprintf(&target, "<xsd:element name=\"changed%d\"/>\n", i)
+		} else {
+			fmt.Fprintf(&target, "<xsd:element name=\"field%d\"/>\n", i)
+		}
+	}
+
+	diff := UnifiedDiff([]byte(source.String()), []byte(target.String()), "a/file.xsd", "b/file.xsd")
+	if count := strings.Count(diff, "\n+<xsd:element name=\"changed"); count != 20 {
+		t.Errorf("Expected 20 added lines, got %d", count)
+	}
+	if count := strings.Count(diff, "\n-<xsd:element"); count != 20 {
+		t.Errorf("Expected 20 removed lines, got %d", count)
+	}
+	if !strings.Contains(diff, "@@ -18498,7 +18498,7 @@\n") {
+		t.Errorf("Expected hunk for line 18501, got\n%s", diff)
+	}
+}
+
+func TestLint(t *testing.T) {
+	flow := strings.Replace(testIntegrationFlow, "https://{{Host}}/{{Endpoint}}", "https://backend.example.com/orders", 1)
+	flow = strings.Replace(flow, `<ifl:property><key>Direction</key><value>Sender</value></ifl:property>`,
+		`<ifl:property><key>Direction</key><value>Sender</value></ifl:property><ifl:property><key>privateKeyAlias</key><value>sap_cloudintegrationcertificate</value></ifl:property>`, 1)
+	flow = strings.Replace(flow, `<key>namespaceMapping</key><value/>`, `<key>ServerTrace</key><value>true</value>`, 1)
+	flow = strings.Replace(flow, "subProcess", "callActivity", -1)
+
+	artifact, err := Parse(newTestArchive(t, map[string]string{
+		"src/main/resources/scenarioflows/integrationflow/Replicate_Orders.iflw": flow,
+		"src/main/resources/parameters.prop":                                     testParameters,
+		"src/main/resources/script/prepare.groovy":                               "def messageLog = messageLogFactory.getMessageLog(message)\nmessageLog.addAttachmentAsString(\"Payload\", body, \"text/plain\")\n",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []Finding{
+		{Rule: "hardcoded-endpoint", Severity: SeverityError, Location: "MessageFlow_2(HTTP)"},
+		{Rule: "credential-not-parameterized", Severity: SeverityWarning, Location: "MessageFlow_1(HTTPS)"},
+		{Rule: "debug-log-level", Severity: SeverityWarning, Location: "src/main/resources/scenarioflows/integrationflow/Replicate_Orders.iflw"},
+		{Rule: "missing-exception-subprocess", Severity: SeverityWarning, Location: "Integration Process"},
+		{Rule: "script-logs-payload", Severity: SeverityWarning, Location: "src/main/resources/script/prepare.groovy:2"},
+		{Rule: "unused-parameter", Severity: SeverityInfo, Location: parametersPath},
+		{Rule: "unused-parameter", Severity: SeverityInfo, Location: parametersPath},
+		{Rule: "unused-parameter", Severity: SeverityInfo, Location: parametersPath},
+	}
+	findings := Lint(artifact, "Replicate_Orders", nil)
+	if len(findings) != len(expected) {
+		t.Fatal("Unexpected findings ", findings)
+	}
+	for index, finding := range findings {
+		if finding.Rule != expected[index].Rule || finding.Severity != expected[index].Severity || finding.Location != expected[index].Location {
+			t.Errorf("Expected finding %v, got %v", expected[index], *finding)
+		}
+	}
+
+	//Rules are disabled, excluded or get another severity in config
+	config := &LintConfig{
+		FailOn: SeverityWarning,
+		Rules: map[string]*RuleConfig{
+			"hardcoded-endpoint":           {Exclude: []string{"Replicate_*"}},
+			"unused-parameter":             {Severity: SeverityOff},
+			"debug-log-level":              {Severity: SeverityInfo},
+			"credential-not-parameterized": {Severity: SeverityInfo},
+			"missing-exception-subprocess": {Severity: SeverityInfo},
+		},
+	}
+	findings = Lint(artifact, "Replicate_Orders", config)
+	if len(findings) != 4 || findings[0].Rule != "credential-not-parameterized" || findings[0].Severity != SeverityInfo {
+		t.Fatal("Unexpected findings with config ", findings)
+	}
+	if !config.Failed(findings) {
+		t.Error("Expected lint to fail on warning of script-logs-payload")
+	}
+	config.Rules["script-logs-payload"] = &RuleConfig{Severity: SeverityInfo}
+	if config.Failed(Lint(artifact, "Replicate_Orders", config)) {
+		t.Error("Expected lint to pass with info findings only")
+	}
+}
+
+func TestValidateParameterValue(t *testing.T) {
+	valid := [][]string{
+		{"true", "xsd:boolean"},
+		{"-15", "xsd:integer"},
+		{"1.5", "xsd:decimal"},
+		{"", "xsd:boolean"},
+		{"anything", "xsd:string"},
+		{"0 0 * * *", "custom:schedule"},
+	}
+	for _, value := range valid {
+		if err := ValidateParameterValue(value[0], value[1]); err != nil {
+			t.Error(err)
+		}
+	}
+
+	invalid := [][]string{
+		{"yes", "xsd:boolean"},
+		{"TRUE", "xsd:boolean"},
+		{"1.5", "xsd:integer"},
+		{"ten", "xsd:double"},
+	}
+	for _, value := range invalid {
+		if err := ValidateParameterValue(value[0], value[1]); err == nil {
+			t.Errorf("Expected error for %s as %s", value[0], value[1])
+		}
+	}
+}
+
+func TestSetParameterValues(t *testing.T) {
+	content := newTestArchive(t, map[string]string{
+		"META-INF/MANIFEST.MF":                  testManifest,
+		"src/main/resources/parameters.prop":    testParameters,
+		"src/main/resources/parameters.propdef": testPropdef,
+	})
+
+	updated, err := SetParameterValues(content, map[string]string{
+		"Host":    "qa.example.com",
+		"Retries": "3",
+		"Note":    " ünïcode #1 = a:b\\c",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	artifact, err := Parse(updated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	properties := string(artifact.Files[parametersPath])
+	if !strings.HasPrefix(properties, "#Store parameters\n#Mon Jan 01 00:00:00 UTC 2022\nHost=qa.example.com\nEndpoint=api/orders\\:v1\n") {
+		t.Error("Expected comments and order of keys to be kept, got\n", properties)
+	}
+
+	expected := map[string]string{"Host": "qa.example.com", "Endpoint": "api/orders:v1", "Timeout": "60", "Retries": "3", "Note": " ünïcode #1 = a:b\\c"}
+	for key, value := range expected {
+		if parameter := artifact.Parameter(key); parameter == nil || parameter.Value != value {
+			t.Errorf("Expected %s=%s, got %v", key, value, parameter)
+		}
+	}
+	if string(artifact.Files[manifestPath]) != testManifest || string(artifact.Files[propdefPath]) != testPropdef {
+		t.Error("Expected other files to be copied without changes")
+	}
+
+	if err := artifact.ValidateParameterValue("Timeout", "soon"); err == nil {
+		t.Error("Expected type of Timeout to be validated against propdef")
+	}
+	if err := artifact.ValidateParameterValue("Endpoint", "soon"); err != nil {
+		t.Error(err)
+	}
+	if err := artifact.ValidateParameterValue("Unknown", "value"); err == nil {
+		t.Error("Expected error for unknown parameter")
+	}
+
+	//Archive without parameters.prop gets new file
+	updated, err = SetParameterValues(newTestArchive(t, map[string]string{"META-INF/MANIFEST.MF": testManifest}), map[string]string{"Host": "qa.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artifact, err = Parse(updated); err != nil || artifact.Parameter("Host") == nil {
+		t.Error("Expected parameters.prop to be created ", err)
+	}
+}
+
+func TestClearParameterValues(t *testing.T) {
+	content := newTestArchive(t, map[string]string{
+		"META-INF/MANIFEST.MF":               testManifest,
+		"src/main/resources/parameters.prop": testParameters,
+	})
+
+	cleared, err := ClearParameterValues(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifact, err := Parse(cleared)
+	if err != nil {
+		t.Fatal(err)
+	}
+	properties := string(artifact.Files[parametersPath])
+	if properties != "#Store parameters\n#Mon Jan 01 00:00:00 UTC 2022\nHost=\nEndpoint=\nTimeout=\n" {
+		t.Error("Expected keys without values, got\n", properties)
+	}
+
+	//Archive without parameters.prop is not changed
+	content = newTestArchive(t, map[string]string{"META-INF/MANIFEST.MF": testManifest})
+	if cleared, err = ClearParameterValues(content); err != nil || string(cleared) != string(content) {
+		t.Error("Expected archive without parameters.prop to be unchanged ", err)
+	}
+}
@@ -0,0 +1,314 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package iflow
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Trifolium-project/landscaper/packages/util"
+)
+
+//Severities of lint findings. Rule with severity off is not executed
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+	SeverityOff     = "off"
+)
+
+var severityLevels = map[string]int{SeverityInfo: 1, SeverityWarning: 2, SeverityError: 3}
+
+//Script adds message body or payload to message processing log
+var payloadLoggingPattern = regexp.MustCompile(`messageLog\s*\.\s*(addAttachmentAsString|addAttachment)\s*\(`)
+
+//Values of log level properties, which should not be transported
+var debugLogLevels = []string{"debug", "trace", "all events"}
+
+//Result of lint rule
+type Finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Location string `json:"location"`
+	Message  string `json:"message"`
+}
+
+//Static check of artifact content
+type Rule struct {
+	Id              string
+	Description     string
+	DefaultSeverity string
+	check           func(artifact *Artifact) []*Finding
+}
+
+//Settings of lint rules from landscape definition
+type LintConfig struct {
+	//Minimal severity, which fails lint
+	FailOn string
+	Rules  map[string]*RuleConfig
+}
+
+type RuleConfig struct {
+	Severity string
+	//IDs or glob patterns of artifacts, which are not checked by rule
+	Exclude []string
+}
+
+//Built-in rules in order of execution
+var Rules = []*Rule{
+	{
+		Id:              "hardcoded-endpoint",
+		Description:     "Address of receiver adapter should be externalized",
+		DefaultSeverity: SeverityError,
+		check:           checkHardcodedEndpoints,
+	},
+	{
+		Id:              "credential-not-parameterized",
+		Description:     "Credential names and key aliases of adapters should be externalized",
+		DefaultSeverity: SeverityWarning,
+		check:           checkCredentials,
+	},
+	{
+		Id:              "debug-log-level",
+		Description:     "Trace or debug log level should not be left in integration flow",
+		DefaultSeverity: SeverityWarning,
+		check:           checkLogLevel,
+	},
+	{
+		Id:              "missing-exception-subprocess",
+		Description:     "Integration process should handle errors in exception subprocess",
+		DefaultSeverity: SeverityWarning,
+		check:           checkExceptionSubprocess,
+	},
+	{
+		Id:              "script-logs-payload",
+		Description:     "Groovy scripts should not add payload to message processing log",
+		DefaultSeverity: SeverityWarning,
+		check:           checkPayloadLogging,
+	},
+	{
+		Id:              "unused-parameter",
+		Description:     "Externalized parameter is not referenced in integration flow",
+		DefaultSeverity: SeverityInfo,
+		check:           checkUnusedParameters,
+	},
+}
+
+//Get built-in rule by ID
+func GetRule(id string) *Rule {
+	for _, rule := range Rules {
+		if rule.Id == id {
+			return rule
+		}
+	}
+	return nil
+}
+
+//Check, that severity is known. Off is accepted only for rules
+func ValidateSeverity(severity string, allowOff bool) error {
+	if _, ok := severityLevels[severity]; ok || (allowOff && severity == SeverityOff) {
+		return nil
+	}
+	return fmt.Errorf("unknown severity %s, use error, warning or info", severity)
+}
+
+//Run rules over artifact. Artifact ID is used to apply exclusions of rules
+func Lint(artifact *Artifact, id string, config *LintConfig) []*Finding {
+	var findings []*Finding
+	for _, rule := range Rules {
+		severity := config.Severity(rule)
+		if severity == SeverityOff || config.isExcluded(rule, id) {
+			continue
+		}
+		for _, finding := range rule.check(artifact) {
+			finding.Rule = rule.Id
+			finding.Severity = severity
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+//Findings contain severity, which fails lint
+func (config *LintConfig) Failed(findings []*Finding) bool {
+	failOn := SeverityError
+	if config != nil && config.FailOn != "" {
+		failOn = config.FailOn
+	}
+	for _, finding := range findings {
+		if severityLevels[finding.Severity] >= severityLevels[failOn] {
+			return true
+		}
+	}
+	return false
+}
+
+//Severity of rule from config, or default severity of rule
+func (config *LintConfig) Severity(rule *Rule) string {
+	if config != nil {
+		if ruleConfig, ok := config.Rules[rule.Id]; ok && ruleConfig.Severity != "" {
+			return ruleConfig.Severity
+		}
+	}
+	return rule.DefaultSeverity
+}
+
+func (config *LintConfig) isExcluded(rule *Rule, id string) bool {
+	if config == nil {
+		return false
+	}
+	ruleConfig, ok := config.Rules[rule.Id]
+	if !ok {
+		return false
+	}
+	for _, pattern := range ruleConfig.Exclude {
+		if matched, _ := path.Match(pattern, id); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func checkHardcodedEndpoints(artifact *Artifact) []*Finding {
+	var findings []*Finding
+	for _, channel := range artifact.Adapters() {
+		if !strings.EqualFold(channel.Direction, "Receiver") {
+			continue
+		}
+		if endpoint := channel.Endpoint(); endpoint != "" && !isParameterized(endpoint) {
+			findings = append(findings, &Finding{
+				Location: channelLocation(channel),
+				Message:  fmt.Sprintf("Address %s is not externalized", endpoint),
+			})
+		}
+	}
+	return findings
+}
+
+func checkCredentials(artifact *Artifact) []*Finding {
+	var findings []*Finding
+	for _, channel := range artifact.Adapters() {
+		for _, key := range sortedKeys(channel.Properties) {
+			value := channel.Properties[key]
+			lowerKey := strings.ToLower(key)
+			//Names of credentials and key aliases, e.g. credentialName or privateKeyAlias
+			isCredential := strings.HasSuffix(lowerKey, "credentialname") || strings.HasSuffix(lowerKey, "alias")
+			if isCredential && value != "" && !isParameterized(value) {
+				findings = append(findings, &Finding{
+					Location: channelLocation(channel),
+					Message:  fmt.Sprintf("%s %s is not externalized", key, value),
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func checkLogLevel(artifact *Artifact) []*Finding {
+	var findings []*Finding
+	for _, flow := range artifact.Flows {
+		if flow.Properties["ServerTrace"] == "true" {
+			findings = append(findings, &Finding{Location: flow.Path, Message: "Server trace is enabled"})
+		}
+		for _, key := range sortedKeys(flow.Properties) {
+			lowerKey := strings.ToLower(key)
+			if lowerKey != "log" && lowerKey != "loglevel" {
+				continue
+			}
+			if value := flow.Properties[key]; isDebugLogLevel(value) {
+				findings = append(findings, &Finding{Location: flow.Path, Message: fmt.Sprintf("Log level is %s", value)})
+			}
+		}
+	}
+	return findings
+}
+
+func checkExceptionSubprocess(artifact *Artifact) []*Finding {
+	var findings []*Finding
+	for _, flow := range artifact.Flows {
+		for _, process := range flow.Processes {
+			//Errors of local integration processes are handled by calling process
+			if process.Properties["processType"] == "directCall" {
+				continue
+			}
+			found := false
+			for _, step := range process.Steps {
+				if step.Kind == "subProcess" && (step.ActivityType == "" || step.ActivityType == "ErrorEventSubProcessTemplate") {
+					found = true
+				}
+			}
+			if !found {
+				findings = append(findings, &Finding{
+					Location: process.Name,
+					Message:  "Exception subprocess is missing",
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func checkPayloadLogging(artifact *Artifact) []*Finding {
+	var findings []*Finding
+	for _, script := range artifact.Scripts {
+		extension := path.Ext(script.Name)
+		if extension != ".groovy" && extension != ".gsh" {
+			continue
+		}
+		for index, line := range splitLines(script.Content) {
+			if payloadLoggingPattern.MatchString(line) {
+				findings = append(findings, &Finding{
+					Location: fmt.Sprintf("%s:%d", script.Path, index+1),
+					Message:  "Payload is added to message processing log",
+				})
+			}
+		}
+	}
+	return findings
+}
+
+func checkUnusedParameters(artifact *Artifact) []*Finding {
+	var findings []*Finding
+	references := artifact.ParameterReferences()
+	for _, parameter := range artifact.Parameters {
+		if !util.Contains(references, parameter.Key) {
+			findings = append(findings, &Finding{
+				Location: parametersPath,
+				Message:  fmt.Sprintf("Parameter %s is not used", parameter.Key),
+			})
+		}
+	}
+	return findings
+}
+
+func isParameterized(value string) bool {
+	return parameterReferencePattern.MatchString(value)
+}
+
+func isDebugLogLevel(value string) bool {
+	for _, level := range debugLogLevels {
+		if strings.EqualFold(value, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func channelLocation(channel *Channel) string {
+	return fmt.Sprintf("%s(%s)", channel.Id, channel.ComponentType)
+}
@@ -0,0 +1,56 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package iflow
+
+import (
+	"strings"
+)
+
+//OSGi bundle manifest of artifact
+type Manifest struct {
+	SymbolicName string
+	Name         string
+	Version      string
+	BundleType   string
+	Attributes   map[string]string
+}
+
+//Parse MANIFEST.MF. Lines, which start with space, continue value of previous attribute
+func ParseManifest(content []byte) *Manifest {
+	manifest := &Manifest{Attributes: make(map[string]string)}
+
+	lastKey := ""
+	for _, line := range strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(line, " ") && lastKey != "" {
+			manifest.Attributes[lastKey] += line[1:]
+			continue
+		}
+		split := strings.SplitN(line, ":", 2)
+		if len(split) != 2 {
+			continue
+		}
+		lastKey = strings.TrimSpace(split[0])
+		manifest.Attributes[lastKey] = strings.TrimSpace(split[1])
+	}
+
+	//Directives, e.g. "; singleton:=true", are not part of symbolic name
+	manifest.SymbolicName = strings.TrimSpace(strings.SplitN(manifest.Attributes["Bundle-SymbolicName"], ";", 2)[0])
+	manifest.Name = manifest.Attributes["Bundle-Name"]
+	manifest.Version = manifest.Attributes["Bundle-Version"]
+	manifest.BundleType = manifest.Attributes["SAP-BundleType"]
+
+	return manifest
+}
@@ -0,0 +1,368 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package iflow
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/Trifolium-project/landscaper/packages/util"
+)
+
+//Externalized parameter: value from parameters.prop and definition from parameters.propdef
+type Parameter struct {
+	Key         string
+	Value       string
+	Type        string
+	Required    bool
+	Description string
+	//Parameter is defined in parameters.propdef
+	Defined bool
+}
+
+type propdefXML struct {
+	Parameters []struct {
+		Name        string `xml:"name"`
+		Type        string `xml:"type"`
+		IsRequired  string `xml:"isRequired"`
+		Description string `xml:"description"`
+	} `xml:"parameter"`
+}
+
+//Merge parameter values with definitions. Order of parameters.prop is kept, parameters, which are only defined, are added after them
+func parseParameters(properties []byte, propdef []byte) ([]*Parameter, error) {
+	var parameters []*Parameter
+	byKey := make(map[string]*Parameter)
+
+	keys, values := ParseProperties(properties)
+	for _, key := range keys {
+		parameter := &Parameter{Key: key, Value: values[key], Type: "xsd:string"}
+		parameters = append(parameters, parameter)
+		byKey[key] = parameter
+	}
+
+	if len(propdef) == 0 {
+		return parameters, nil
+	}
+
+	definitions := propdefXML{}
+	err := xml.Unmarshal(propdef, &definitions)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse parameters.propdef: %s", err)
+	}
+
+	for _, definition := range definitions.Parameters {
+		parameter, ok := byKey[definition.Name]
+		if !ok {
+			parameter = &Parameter{Key: definition.Name}
+			parameters = append(parameters, parameter)
+			byKey[definition.Name] = parameter
+		}
+		if definition.Type != "" {
+			parameter.Type = definition.Type
+		}
+		parameter.Required = definition.IsRequired == "true"
+		parameter.Description = definition.Description
+		parameter.Defined = true
+	}
+
+	return parameters, nil
+}
+
+//Parse Java properties file. Returns keys in order of file and values
+func ParseProperties(content []byte) ([]string, map[string]string) {
+	var keys []string
+	values := make(map[string]string)
+
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+	for index := 0; index < len(lines); index++ {
+		line := strings.TrimLeft(lines[index], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+
+		//Line, which ends with odd number of backslashes, is continued on next line
+		for endsWithContinuation(line) && index+1 < len(lines) {
+			index++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[index], " \t\f")
+		}
+
+		key, value := splitProperty(line)
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+		values[key] = value
+	}
+
+	return keys, values
+}
+
+func endsWithContinuation(line string) bool {
+	count := 0
+	for index := len(line) - 1; index >= 0 && line[index] == '\\'; index-- {
+		count++
+	}
+	return count%2 == 1
+}
+
+//Split line into key and value. Separator is first unescaped "=", ":" or whitespace
+func splitProperty(line string) (string, string) {
+	separator := len(line)
+	for index := 0; index < len(line); index++ {
+		if line[index] == '\\' {
+			index++
+			continue
+		}
+		if line[index] == '=' || line[index] == ':' || line[index] == ' ' || line[index] == '\t' {
+			separator = index
+			break
+		}
+	}
+
+	key := line[:separator]
+	rest := strings.TrimLeft(line[separator:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+
+	return unescapeProperty(key), unescapeProperty(rest)
+}
+
+func unescapeProperty(value string) string {
+	var builder strings.Builder
+	for index := 0; index < len(value); index++ {
+		if value[index] != '\\' || index+1 == len(value) {
+			builder.WriteByte(value[index])
+			continue
+		}
+		index++
+		switch value[index] {
+		case 't':
+			builder.WriteByte('\t')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'f':
+			builder.WriteByte('\f')
+		case 'u':
+			if index+4 < len(value) {
+				if code, err := strconv.ParseUint(value[index+1:index+5], 16, 32); err == nil {
+					builder.WriteRune(rune(code))
+					index += 4
+					continue
+				}
+			}
+			builder.WriteByte('u')
+		default:
+			builder.WriteByte(value[index])
+		}
+	}
+	return builder.String()
+}
+
+//Check, that value can be assigned to parameter of data type. Empty value and custom types, e.g. custom:schedule, are not checked
+func ValidateParameterValue(value string, dataType string) error {
+	if value == "" {
+		return nil
+	}
+
+	switch dataType {
+	case "xsd:boolean":
+		if value != "true" && value != "false" {
+			return fmt.Errorf("value %s is not %s, use true or false", value, dataType)
+		}
+	case "xsd:integer", "xsd:int", "xsd:long", "xsd:short":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("value %s is not %s", value, dataType)
+		}
+	case "xsd:decimal", "xsd:double", "xsd:float":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("value %s is not %s", value, dataType)
+		}
+	}
+
+	return nil
+}
+
+//Check value of externalized parameter against parameters.propdef. Parameters without definition accept any value
+func (artifact *Artifact) ValidateParameterValue(key string, value string) error {
+	parameter := artifact.Parameter(key)
+	if parameter == nil {
+		return fmt.Errorf("parameter %s is not found in artifact", key)
+	}
+	if !parameter.Defined {
+		return nil
+	}
+	return ValidateParameterValue(value, parameter.Type)
+}
+
+//Replace values of externalized parameters in parameters.prop of artifact archive. Parameters, which are not in file, are added.
+//Other files of archive are copied without changes
+func SetParameterValues(content []byte, values map[string]string) ([]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read artifact archive: %s", err)
+	}
+
+	buffer := new(bytes.Buffer)
+	writer := zip.NewWriter(buffer)
+
+	found := false
+	for _, file := range reader.File {
+		if file.Name != parametersPath {
+			if err := writer.Copy(file); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		found = true
+
+		properties, err := readZipFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := writeZipFile(writer, file.FileHeader, updateProperties(properties, values)); err != nil {
+			return nil, err
+		}
+	}
+
+	if !found {
+		header := zip.FileHeader{Name: parametersPath, Method: zip.Deflate}
+		if err := writeZipFile(writer, header, updateProperties(nil, values)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+//Remove values of externalized parameters from parameters.prop of artifact archive, so archive can be stored without secrets.
+//Keys are kept, archive without parameters.prop is returned unchanged
+func ClearParameterValues(content []byte) ([]byte, error) {
+	files, err := util.ReadZipEntries(content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read artifact archive: %s", err)
+	}
+	properties, ok := files[parametersPath]
+	if !ok {
+		return content, nil
+	}
+
+	keys, _ := ParseProperties(properties)
+	values := make(map[string]string)
+	for _, key := range keys {
+		values[key] = ""
+	}
+	return SetParameterValues(content, values)
+}
+
+//Format Java properties file, keys are written in given order
+func FormatProperties(keys []string, values map[string]string) []byte {
+	var builder strings.Builder
+	for _, key := range keys {
+		builder.WriteString(escapeProperty(key, true))
+		builder.WriteByte('=')
+		builder.WriteString(escapeProperty(values[key], false))
+		builder.WriteByte('\n')
+	}
+	return []byte(builder.String())
+}
+
+//Set values in properties file. Leading comments and order of existing keys are kept, new keys are added in sorted order
+func updateProperties(content []byte, values map[string]string) []byte {
+	keys, current := ParseProperties(content)
+
+	var newKeys []string
+	for key, value := range values {
+		if _, ok := current[key]; !ok {
+			newKeys = append(newKeys, key)
+		}
+		current[key] = value
+	}
+	sort.Strings(newKeys)
+	keys = append(keys, newKeys...)
+
+	var header strings.Builder
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		trimmed := strings.TrimLeft(line, " \t\f")
+		if trimmed == "" || (trimmed[0] != '#' && trimmed[0] != '!') {
+			break
+		}
+		header.WriteString(line)
+	}
+
+	return append([]byte(header.String()), FormatProperties(keys, current)...)
+}
+
+//Escape key or value of properties file. Characters outside of ISO 8859-1 printable range are written as unicode escapes
+func escapeProperty(value string, isKey bool) string {
+	var builder strings.Builder
+	for index, char := range value {
+		switch {
+		case char == '\\':
+			builder.WriteString(`\\`)
+		case char == '\t':
+			builder.WriteString(`\t`)
+		case char == '\n':
+			builder.WriteString(`\n`)
+		case char == '\r':
+			builder.WriteString(`\r`)
+		case char == '\f':
+			builder.WriteString(`\f`)
+		case char == '=' || char == ':' || char == '#' || char == '!':
+			builder.WriteByte('\\')
+			builder.WriteRune(char)
+		case char == ' ' && (isKey || index == 0):
+			builder.WriteString(`\ `)
+		case char < 0x20 || char > 0x7e:
+			for _, unit := range utf16.Encode([]rune{char}) {
+				fmt.Fprintf(&builder, "\\u%04X", unit)
+			}
+		default:
+			builder.WriteRune(char)
+		}
+	}
+	return builder.String()
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+func writeZipFile(writer *zip.Writer, header zip.FileHeader, content []byte) error {
+	fileWriter, err := writer.CreateHeader(&header)
+	if err != nil {
+		return err
+	}
+	_, err = fileWriter.Write(content)
+	return err
+}
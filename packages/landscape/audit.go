@@ -0,0 +1,94 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package landscape
+
+import (
+	"fmt"
+
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/iflow"
+)
+
+//Issues of declared configuration
+const (
+	AuditUnknownKey           = "UNKNOWN KEY"
+	AuditTypeMismatch         = "TYPE MISMATCH"
+	AuditUndeclaredDifference = "UNDECLARED DIFFERENCE"
+	AuditOriginalUnavailable  = "ORIGINAL UNAVAILABLE"
+)
+
+type AuditFinding struct {
+	Key     string
+	Issue   string
+	Details string
+}
+
+//Compare declared configuration with keys and data types of artifact version in tenant.
+//If configuration of original environment is given, parameters, which differ from it without declaration, are reported
+func AuditConfiguration(declared []*Parameter, tenant []*cpiclient.Configuration, original []*cpiclient.Configuration) []*AuditFinding {
+	var findings []*AuditFinding
+
+	tenantByKey := make(map[string]*cpiclient.Configuration)
+	for _, conf := range tenant {
+		tenantByKey[conf.ParameterKey] = conf
+	}
+
+	declaredKeys := make(map[string]bool)
+	for _, parameter := range declared {
+		declaredKeys[parameter.Key] = true
+
+		conf, ok := tenantByKey[parameter.Key]
+		if !ok {
+			//Package defaults are applied only to artifacts, which have such parameter
+			if !parameter.Inherited {
+				findings = append(findings, &AuditFinding{Key: parameter.Key, Issue: AuditUnknownKey, Details: "Parameter is not found in artifact"})
+			}
+			continue
+		}
+
+		//Type is xsd:string, if it is not set in landscape file
+		if parameter.Type != "" && parameter.Type != "xsd:string" && parameter.Type != conf.DataType {
+			findings = append(findings, &AuditFinding{
+				Key:     parameter.Key,
+				Issue:   AuditTypeMismatch,
+				Details: fmt.Sprintf("Declared type is %s, artifact type is %s", parameter.Type, conf.DataType),
+			})
+			continue
+		}
+
+		//Secret values are not resolved and not printed
+		if parameter.Sensitive || IsSecretReference(parameter.Value) {
+			continue
+		}
+		if err := iflow.ValidateParameterValue(parameter.Value, conf.DataType); err != nil {
+			findings = append(findings, &AuditFinding{Key: parameter.Key, Issue: AuditTypeMismatch, Details: err.Error()})
+		}
+	}
+
+	for _, originalConf := range original {
+		conf, ok := tenantByKey[originalConf.ParameterKey]
+		if !ok || declaredKeys[conf.ParameterKey] || conf.ParameterValue == originalConf.ParameterValue {
+			continue
+		}
+		findings = append(findings, &AuditFinding{
+			Key:     conf.ParameterKey,
+			Issue:   AuditUndeclaredDifference,
+			Details: fmt.Sprintf("Value %s differs from original environment value %s", conf.ParameterValue, originalConf.ParameterValue),
+		})
+	}
+
+	return findings
+}
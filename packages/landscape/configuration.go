@@ -0,0 +1,98 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package landscape
+
+import (
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+)
+
+//State of configuration parameter in tenant compared to landscape definition
+type ParameterState struct {
+	Key           string
+	Type          string
+	TenantValue   string
+	InTenant      bool
+	DeclaredValue string
+	Declared      bool
+	OriginalValue string
+	InOriginal    bool
+	Drifted       bool
+	//Declared value is secret, values should not be printed
+	Sensitive bool
+}
+
+//Expected value of parameter - declared in landscape, or copied from original environment
+func (state *ParameterState) ExpectedValue() string {
+	if state.Declared {
+		return state.DeclaredValue
+	}
+	return state.OriginalValue
+}
+
+//Compare configuration of artifact in tenant with declared parameters and configuration in original environment.
+//Parameters, that are not declared in landscape, are expected to be the same as in original environment
+func CompareConfiguration(tenant []*cpiclient.Configuration, declared []*Parameter, original []*cpiclient.Configuration) []*ParameterState {
+	var states []*ParameterState
+	statesByKey := make(map[string]*ParameterState)
+
+	for _, conf := range tenant {
+		state := &ParameterState{
+			Key:         conf.ParameterKey,
+			Type:        conf.DataType,
+			TenantValue: conf.ParameterValue,
+			InTenant:    true,
+		}
+		states = append(states, state)
+		statesByKey[state.Key] = state
+	}
+
+	for _, parameter := range declared {
+		state, ok := statesByKey[parameter.Key]
+		//Package defaults are applied only to artifacts, which have such parameter
+		if !ok && parameter.Inherited {
+			continue
+		}
+		if !ok {
+			state = &ParameterState{
+				Key:  parameter.Key,
+				Type: parameter.Type,
+			}
+			states = append(states, state)
+			statesByKey[state.Key] = state
+		}
+		state.DeclaredValue = parameter.Value
+		state.Declared = true
+		state.Sensitive = parameter.Sensitive || IsSecretReference(parameter.Value)
+	}
+
+	for _, conf := range original {
+		if state, ok := statesByKey[conf.ParameterKey]; ok {
+			state.OriginalValue = conf.ParameterValue
+			state.InOriginal = true
+		}
+	}
+
+	for _, state := range states {
+		switch {
+		case !state.InTenant:
+			state.Drifted = true
+		case state.Declared || state.InOriginal:
+			state.Drifted = state.TenantValue != state.ExpectedValue()
+		}
+	}
+
+	return states
+}
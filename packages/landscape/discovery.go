@@ -0,0 +1,104 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package landscape
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+)
+
+const maxSuffixLength = 10
+
+//Detect environment suffixes in list of IDs of existing tenant. Suffix is detected, when list contains both "Id" and "Id" + suffix.
+//Suffix should not start with lowercase letter, otherwise "Order" and "Orders" would produce suffix "s"
+func DetectSuffixes(ids []string) []string {
+	idSet := make(map[string]bool)
+	for _, id := range ids {
+		idSet[id] = true
+	}
+
+	suffixSet := make(map[string]bool)
+	for _, id := range ids {
+		for length := 1; length <= maxSuffixLength && length < len(id); length++ {
+			base := id[:len(id)-length]
+			suffix := id[len(id)-length:]
+			if !idSet[base] || !isSuffixCandidate(suffix) {
+				continue
+			}
+			suffixSet[suffix] = true
+		}
+	}
+
+	var suffixes []string
+	for suffix := range suffixSet {
+		suffixes = append(suffixes, suffix)
+	}
+	//Longer suffixes first, so that "PREPROD" is checked before "PROD"
+	sort.Slice(suffixes, func(i, j int) bool {
+		if len(suffixes[i]) != len(suffixes[j]) {
+			return len(suffixes[i]) > len(suffixes[j])
+		}
+		return suffixes[i] < suffixes[j]
+	})
+
+	return suffixes
+}
+
+func isSuffixCandidate(suffix string) bool {
+	first := rune(suffix[0])
+	if unicode.IsLower(first) {
+		return false
+	}
+	return !strings.ContainsAny(suffix, " .")
+}
+
+//Split ID to base ID and one of suffixes. Empty suffix is returned, if ID has no suffix from the list
+func SplitSuffix(id string, suffixes []string) (string, string) {
+	for _, suffix := range suffixes {
+		if suffix != "" && strings.HasSuffix(id, suffix) && len(id) > len(suffix) {
+			return strings.TrimSuffix(id, suffix), suffix
+		}
+	}
+	return id, ""
+}
+
+//Get parameters, which values differ from original environment
+func DiffParameters(configurations []*cpiclient.Configuration, original []*cpiclient.Configuration) []ParameterYAML {
+	originalValues := make(map[string]string)
+	for _, conf := range original {
+		originalValues[conf.ParameterKey] = conf.ParameterValue
+	}
+
+	var parameters []ParameterYAML
+	for _, conf := range configurations {
+		if value, ok := originalValues[conf.ParameterKey]; ok && value == conf.ParameterValue {
+			continue
+		}
+		parameter := ParameterYAML{
+			Key:   conf.ParameterKey,
+			Value: conf.ParameterValue,
+		}
+		if conf.DataType != "xsd:string" {
+			parameter.Type = conf.DataType
+		}
+		parameters = append(parameters, parameter)
+	}
+
+	return parameters
+}
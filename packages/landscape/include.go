@@ -0,0 +1,199 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package landscape
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+//Directory with additional landscape files, relative to main landscape file
+const includeDirectory = "landscape.d"
+
+//Files, where entries of merged landscape definition are declared
+type landscapeSources struct {
+	packages     map[string]string
+	artifacts    map[string]string
+	systems      map[string]string
+	environments map[string]string
+}
+
+//Read main landscape file with all included files and merge them into one definition. Files are listed in "include"
+//section of main file, or placed into landscape.d directory next to it. Returns merged definition and file name for each package
+func ReadLandscapeYAML(configFile string) (*LandscapeYAML, map[string]string, error) {
+	merged, err := readLandscapeYAMLFile(configFile)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files, err := getIncludedFiles(configFile, merged.Landscape.Include)
+	if err != nil {
+		return nil, nil, err
+	}
+	merged.Landscape.Include = nil
+
+	sources := &landscapeSources{
+		packages:     map[string]string{},
+		artifacts:    map[string]string{},
+		systems:      map[string]string{},
+		environments: map[string]string{},
+	}
+
+	main := *merged
+	merged.Landscape.Systems = nil
+	merged.Landscape.Packages = nil
+	merged.Landscape.Environments = nil
+
+	err = mergeLandscapeYAML(merged, &main, configFile, sources)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, fileName := range files {
+		included, err := readLandscapeYAMLFile(fileName)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(included.Landscape.Include) > 0 {
+			return nil, nil, fmt.Errorf("nested include is not supported, remove include section from %s", fileName)
+		}
+
+		err = mergeLandscapeYAML(merged, included, fileName, sources)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return merged, sources.packages, nil
+}
+
+func readLandscapeYAMLFile(fileName string) (*LandscapeYAML, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	landscapeYAML := &LandscapeYAML{}
+	err = yaml.Unmarshal(content, landscapeYAML)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %s", fileName, err)
+	}
+
+	return landscapeYAML, nil
+}
+
+//Get list of included files. Patterns are relative to main landscape file, files from landscape.d directory are added after them
+func getIncludedFiles(configFile string, patterns []string) ([]string, error) {
+	dir := filepath.Dir(configFile)
+
+	var files []string
+	added := map[string]bool{filepath.Clean(configFile): true}
+	add := func(matches []string) {
+		for _, match := range matches {
+			if !added[filepath.Clean(match)] {
+				added[filepath.Clean(match)] = true
+				files = append(files, match)
+			}
+		}
+	}
+
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid include pattern %s: %s", pattern, err)
+		}
+		if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+			return nil, fmt.Errorf("included file %s is not found", pattern)
+		}
+		add(matches)
+	}
+
+	for _, extension := range []string{"*.yaml", "*.yml"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, includeDirectory, extension))
+		add(matches)
+	}
+
+	return files, nil
+}
+
+//Append entries of included definition to merged one. Duplicate IDs are reported as conflicts
+func mergeLandscapeYAML(merged *LandscapeYAML, included *LandscapeYAML, fileName string, sources *landscapeSources) error {
+	content := &included.Landscape
+
+	if content.Name != "" {
+		if merged.Landscape.Name != "" && merged.Landscape.Name != content.Name {
+			return fmt.Errorf("landscape name %s in %s conflicts with %s", content.Name, fileName, merged.Landscape.Name)
+		}
+		merged.Landscape.Name = content.Name
+	}
+
+	if content.OriginalEnvironment != "" {
+		if merged.Landscape.OriginalEnvironment != "" && merged.Landscape.OriginalEnvironment != content.OriginalEnvironment {
+			return fmt.Errorf("original environment %s in %s conflicts with %s", content.OriginalEnvironment, fileName, merged.Landscape.OriginalEnvironment)
+		}
+		merged.Landscape.OriginalEnvironment = content.OriginalEnvironment
+	}
+
+	if content.Lint != nil {
+		if merged.Landscape.Lint != nil {
+			return fmt.Errorf("lint rules in %s conflict with lint rules, which are already declared", fileName)
+		}
+		merged.Landscape.Lint = content.Lint
+	}
+
+	for _, systemYAML := range content.Systems {
+		if source, ok := sources.systems[systemYAML.Id]; ok {
+			return fmt.Errorf("system %s is declared in %s and %s", systemYAML.Id, source, fileName)
+		}
+		sources.systems[systemYAML.Id] = fileName
+		merged.Landscape.Systems = append(merged.Landscape.Systems, systemYAML)
+	}
+
+	for _, environmentYAML := range content.Environments {
+		if source, ok := sources.environments[environmentYAML.Id]; ok {
+			return fmt.Errorf("environment %s is declared in %s and %s", environmentYAML.Id, source, fileName)
+		}
+		sources.environments[environmentYAML.Id] = fileName
+		merged.Landscape.Environments = append(merged.Landscape.Environments, environmentYAML)
+	}
+
+	for _, packageYAML := range content.Packages {
+		if source, ok := sources.packages[packageYAML.Id]; ok {
+			return fmt.Errorf("package %s is declared in %s and %s", packageYAML.Id, source, fileName)
+		}
+		sources.packages[packageYAML.Id] = fileName
+
+		//Artifact IDs are unique within tenant, so they should not be repeated in different packages
+		for _, artifactYAML := range packageYAML.Artifacts {
+			location := fmt.Sprintf("%s(package %s)", fileName, packageYAML.Id)
+			if source, ok := sources.artifacts[artifactYAML.Id]; ok {
+				return fmt.Errorf("artifact %s is declared in %s and %s", artifactYAML.Id, source, location)
+			}
+			sources.artifacts[artifactYAML.Id] = location
+		}
+
+		merged.Landscape.Packages = append(merged.Landscape.Packages, packageYAML)
+	}
+
+	return nil
+}
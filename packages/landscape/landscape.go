@@ -18,15 +18,15 @@ package landscape
 import (
 	"bufio"
 	"fmt"
-	"log"
 	"os"
+	"sort"
 	"strings"
 	"syscall"
 
 	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"github.com/Trifolium-project/landscaper/packages/iflow"
 	"github.com/joho/godotenv"
 	"golang.org/x/term"
-	"gopkg.in/yaml.v3"
 )
 
 //import cpiclient
@@ -36,12 +36,17 @@ type Landscape struct {
 	Packages            map[string]*Package
 	Environments        map[string]*Environment
 	OriginalEnvironment *Environment
+	FileName            string
+	Lint                *iflow.LintConfig
 }
 
 type System struct {
-	Id     string
-	Name   string
-	Client *cpiclient.CPIClient
+	Id               string
+	Name             string
+	Host             string
+	LoginVariable    string
+	PasswordVariable string
+	Client           *cpiclient.CPIClient
 }
 
 type Environment struct {
@@ -49,17 +54,26 @@ type Environment struct {
 	Name   string
 	Suffix string
 	System *System
+	Naming NamingRules
+	Vars   map[string]string
 }
 
 type Package struct {
 	Id string
 	Artifacts map[string]*Artifact
+	//Resolved default configuration for each environment, inherited by all artifacts of package
+	Defaults map[string]*Configuration
+	FileName string
 }
 
 type Artifact struct {
 	Id string
+	PackageId string
 	Template string
 	Configurations map[string]*Configuration
+	ParameterMapping *ParameterMapping
+	//Template version, from which implementation was built, for each environment
+	TemplateBases map[string]*TemplateBase
 }
 
 type Configuration struct {
@@ -71,58 +85,98 @@ type Parameter struct {
 	Key string
 	Value string
 	Type string
+	//Parameter is inherited from package defaults, and can be missing in artifact
+	Inherited bool
+	//Value is resolved from secret reference and should not be printed
+	Sensitive bool
 }
 
 
 
 type LandscapeYAML struct {
-	Landscape struct { 
-		Name string
-		Systems []struct {
-			Id string 
-			Name string
-			Host string
-			Login string
-			Password string
-		}
-		Packages []struct{
-			Id string
-			Artifacts []struct{
-				Id string
-				Template string
-				Configurations []struct{
-					Environment string
-					Parameters []struct{
-						Key string
-						Value string
-						Type string
-					}
-				}
-			}
-		}
-		Environments []struct{
-			Id string
-			Name string
-			Suffix string
-			System string
-		}
-		OriginalEnvironment string `yaml:"originalEnvironment"`
-	}
+	Landscape LandscapeContentYAML `yaml:"landscape"`
 }
 
+type LandscapeContentYAML struct {
+	Name                string            `yaml:"name,omitempty"`
+	Include             []string          `yaml:"include,omitempty"`
+	Systems             []SystemYAML      `yaml:"systems,omitempty"`
+	Packages            []PackageYAML     `yaml:"packages,omitempty"`
+	Environments        []EnvironmentYAML `yaml:"environments,omitempty"`
+	OriginalEnvironment string            `yaml:"originalEnvironment,omitempty"`
+	Lint                *LintYAML         `yaml:"lint,omitempty"`
+}
+
+type SystemYAML struct {
+	Id       string `yaml:"id"`
+	Name     string `yaml:"name,omitempty"`
+	Host     string `yaml:"host"`
+	Login    string `yaml:"login"`
+	Password string `yaml:"password"`
+}
 
+type PackageYAML struct {
+	Id        string              `yaml:"id"`
+	Defaults  []ConfigurationYAML `yaml:"defaults,omitempty"`
+	Artifacts []ArtifactYAML      `yaml:"artifacts,omitempty"`
+}
 
+type ArtifactYAML struct {
+	Id               string                `yaml:"id"`
+	Template         string                `yaml:"template,omitempty"`
+	ParameterMapping *ParameterMappingYAML `yaml:"parameterMapping,omitempty"`
+	Configurations   []ConfigurationYAML   `yaml:"configurations,omitempty"`
+	TemplateBase     []TemplateBaseYAML    `yaml:"templateBase,omitempty"`
+}
+
+type ConfigurationYAML struct {
+	Environment string          `yaml:"environment,omitempty"`
+	Parameters  []ParameterYAML `yaml:"parameters"`
+}
+
+type ParameterYAML struct {
+	Key       string `yaml:"key"`
+	Value     string `yaml:"value"`
+	Type      string `yaml:"type,omitempty"`
+	//Set for package defaults, when landscape is resolved. Not part of landscape file
+	Inherited bool   `yaml:"-"`
+}
+
+type EnvironmentYAML struct {
+	Id     string            `yaml:"id"`
+	Name   string            `yaml:"name,omitempty"`
+	Suffix string            `yaml:"suffix"`
+	System string            `yaml:"system"`
+	Naming *NamingYAML       `yaml:"naming,omitempty"`
+	Vars   map[string]string `yaml:"vars,omitempty"`
+}
+
+
+
+//Get declared configuration of artifact in environment. Empty, if package, artifact or configuration is not declared
 func(landscape *Landscape) GetArtifactConfiguration(environment string, pkg string, artifact string) ([]*Parameter, error) {
 
-	defer func() {
-        if err := recover(); err != nil {
-            log.Printf("Configuration not found for package %s, artifact %s, env %s. Using config from original environment", pkg, artifact, environment)
-        }
-    }()
+	//Package is not declared in landscape
+	package_, ok := landscape.Packages[pkg]
+	if !ok {
+		return nil, nil
+	}
+
+	//Artifacts, which are not declared, inherit package defaults
+	artifact_, ok := package_.Artifacts[artifact]
+	if !ok {
+		if defaults, ok := package_.Defaults[environment]; ok {
+			return defaults.Parameters, nil
+		}
+		return nil, nil
+	}
 
-	config := landscape.Packages[pkg].Artifacts[artifact].Configurations[environment].Parameters
+	configuration, ok := artifact_.Configurations[environment]
+	if !ok {
+		return nil, nil
+	}
 
-	return config, nil
+	return configuration.Parameters, nil
 }
 
 
@@ -151,12 +205,15 @@ func(landscape *Landscape) GetEnvironment(environment string) (*Environment, err
 	return env, nil
 }
 
-//Get list of artifacts, that are based on selected template
-func (landscape *Landscape) GetArtifactsByTemplate(template string) ([]*Artifact) {
+//Get list of artifacts, that are based on selected template, sorted by ID. If package is not empty, only its artifacts are returned
+func (landscape *Landscape) GetArtifactsByTemplate(template string, pkg string) ([]*Artifact) {
 	var artifactList []*Artifact
 
-	for _, pkg := range landscape.Packages {
-		for _, artifact := range pkg.Artifacts {
+	for _, package_ := range landscape.Packages {
+		if pkg != "" && package_.Id != pkg {
+			continue
+		}
+		for _, artifact := range package_.Artifacts {
 			if(artifact.Template == template){
 				artifactList = append(artifactList, artifact)
 				//add artifact to return array
@@ -165,6 +222,10 @@ func (landscape *Landscape) GetArtifactsByTemplate(template string) ([]*Artifact
 		}
 	}
 
+	sort.Slice(artifactList, func(i, j int) bool {
+		return artifactList[i].Id < artifactList[j].Id
+	})
+
 	return artifactList
 
 }
@@ -176,27 +237,34 @@ func NewLandscape(configFile string) (*Landscape, error) {
 		configFile = "conf/landscape-prod.yaml"
 	}
     
-	config, err := os.ReadFile(configFile)
-	
-	if err != nil {
-		return nil, err
-    }
-	//log.Println(string(config))
-
-	landscape := LandscapeYAML{}
-    err = yaml.Unmarshal(config, &landscape)
 
+	//Main file is merged with included files
+	landscape, packageFiles, err := ReadLandscapeYAML(configFile)
 	if err != nil {
 		return nil, err
 	}
 	//fmt.Println(string(landscape.Landscape.Packages[0].Artifacts[0].Configurations[0].Parameters[0].Key))
 	//fmt.Println(string(landscape.Landscape.Packages[0].Artifacts[0].Configurations[0].Parameters[0].Value))
 
-	return buildLandscapeFromManifest(&landscape)
+	result, err := buildLandscapeFromManifest(landscape)
+	if err != nil {
+		return nil, err
+	}
+	result.FileName = configFile
+	for packageId, package_ := range result.Packages {
+		package_.FileName = packageFiles[packageId]
+	}
+
+	return result, nil
 }
 
 func buildLandscapeFromManifest(landscapeYaml *LandscapeYAML) (*Landscape, error) {
 
+	//Package defaults and variables are resolved before systems are created, so that errors are reported without login
+	landscapeYaml, err := ResolveLandscapeYAML(landscapeYaml)
+	if err != nil {
+		return nil, err
+	}
 
 	systems :=  map[string]*System{}
 	packages :=  map[string]*Package{} 
@@ -205,55 +273,12 @@ func buildLandscapeFromManifest(landscapeYaml *LandscapeYAML) (*Landscape, error
 
 	//Create systems
 	for _, systemYAML := range landscapeYaml.Landscape.Systems {
-		system := &System{}
-		system.Id = systemYAML.Id
-		system.Name = systemYAML.Name
-
-		login, err := getEnvVariableValue(systemYAML.Login)
+		system, err := NewSystem(systemYAML)
 		if err != nil {
 			return nil, err
 		}
-		
-		password, err := getEnvVariableValue(systemYAML.Password)
-		if err != nil {
-			return nil, err
-		}
-
-
-		reader := bufio.NewReader(os.Stdin)
-
-		if login == ""{
-			fmt.Printf("Please enter login for system %s:\n", system.Name)
-			login, _ = reader.ReadString('\n')
-		}
-		if password == ""{
-			fmt.Printf("Please enter password for system %s:\n", system.Name)
-			//password, _ = reader.ReadString('\n')
-			bytePassword, err := term.ReadPassword(int(syscall.Stdin))
-			if err != nil {
-				return  nil , err
-			}
-
-			password = string(bytePassword)
-		}
-
 
-		system.Client = cpiclient.NewCPIBasicAuthClient(strings.TrimSpace(login), strings.TrimSpace(password), systemYAML.Host, false)
-		
 		systems[system.Id] = system
-		
-		
-		//TODO: Implement check connection
-		/*
-		log.Println("Checking connection...")
-		
-		err = system.Client.CheckConnection()
-		if err != nil {
-			log.Fatalln(err)
-			//return nil, err
-		}
-		*/
-		
 	}
 
 	//Create packages
@@ -263,34 +288,17 @@ func buildLandscapeFromManifest(landscapeYaml *LandscapeYAML) (*Landscape, error
 
 			configurations := make(map[string]*Configuration)
 			for _, configurationYAML := range artifactYAML.Configurations {
-
-				parameters := []*Parameter{}
-				for _, parameterYAML := range configurationYAML.Parameters {
-					paramType := parameterYAML.Type
-					if paramType == "" {
-						paramType = "xsd:string"
-					}
-					parameter := &Parameter{
-						Key: parameterYAML.Key,
-						Value: parameterYAML.Value,
-						Type: paramType,
-					}
-					parameters = append(parameters, parameter)
-					
-				}
-				
-				configuration := &Configuration{
-					Environment: configurationYAML.Environment,
-					Parameters: parameters,
-				}
+				configuration := newConfiguration(configurationYAML)
 				configurations[configuration.Environment] = configuration
-				
 			}
 
 			artifact := &Artifact{
 				Id: artifactYAML.Id,
+				PackageId: packageYAML.Id,
 				Template: artifactYAML.Template,
 				Configurations: configurations,
+				ParameterMapping: newParameterMapping(artifactYAML.ParameterMapping),
+				TemplateBases: newTemplateBases(artifactYAML.TemplateBase),
 			}
 
 			artifacts[artifactYAML.Id] = artifact
@@ -301,9 +309,16 @@ func buildLandscapeFromManifest(landscapeYaml *LandscapeYAML) (*Landscape, error
 
 
 		
+		defaults := make(map[string]*Configuration)
+		for _, configurationYAML := range packageYAML.Defaults {
+			configuration := newConfiguration(configurationYAML)
+			defaults[configuration.Environment] = configuration
+		}
+
 		package_ := &Package{
 			Id: packageYAML.Id,
 			Artifacts: artifacts,
+			Defaults: defaults,
 		}
 
 		packages[package_.Id] = package_
@@ -312,12 +327,7 @@ func buildLandscapeFromManifest(landscapeYaml *LandscapeYAML) (*Landscape, error
 	//Create environments
 	for _, environmentYAML := range landscapeYaml.Landscape.Environments {
 		
-		environment := &Environment{
-			Id: environmentYAML.Id,
-			Name: environmentYAML.Name,
-			Suffix: environmentYAML.Suffix,
-			System: systems[environmentYAML.System],
-		}
+		environment := NewEnvironment(environmentYAML, systems[environmentYAML.System])
 		//fmt.Printf("'%s'\n", environment.Id)
 		
 		environments[environment.Id] = environment
@@ -325,17 +335,111 @@ func buildLandscapeFromManifest(landscapeYaml *LandscapeYAML) (*Landscape, error
 
 
 
+	lint, err := newLintConfig(landscapeYaml.Landscape.Lint)
+	if err != nil {
+		return nil, err
+	}
+
 	landscape := &Landscape{
 		Name: landscapeYaml.Landscape.Name,
 		Systems: systems,
 		Packages: packages,
 		Environments: environments,
 		OriginalEnvironment: environments[landscapeYaml.Landscape.OriginalEnvironment],
+		Lint: lint,
 	}
 	
 	return landscape, nil
 }
 
+func newConfiguration(configurationYAML ConfigurationYAML) *Configuration {
+	parameters := []*Parameter{}
+	for _, parameterYAML := range configurationYAML.Parameters {
+		paramType := parameterYAML.Type
+		if paramType == "" {
+			paramType = "xsd:string"
+		}
+		parameter := &Parameter{
+			Key: parameterYAML.Key,
+			Value: parameterYAML.Value,
+			Type: paramType,
+			Inherited: parameterYAML.Inherited,
+		}
+		parameters = append(parameters, parameter)
+	}
+
+	return &Configuration{
+		Environment: configurationYAML.Environment,
+		Parameters: parameters,
+	}
+}
+
+//Environment with naming rules of landscape file. System can be nil, if only naming conventions are used
+func NewEnvironment(environmentYAML EnvironmentYAML, system *System) *Environment {
+	return &Environment{
+		Id: environmentYAML.Id,
+		Name: environmentYAML.Name,
+		Suffix: environmentYAML.Suffix,
+		System: system,
+		Naming: newNamingRules(environmentYAML.Naming),
+		Vars: environmentYAML.Vars,
+	}
+}
+
+//Create system with CPI client. If credentials are not set in environment variables, they are requested interactively
+func NewSystem(systemYAML SystemYAML) (*System, error) {
+	system := &System{}
+	system.Id = systemYAML.Id
+	system.Name = systemYAML.Name
+	system.Host = systemYAML.Host
+	system.LoginVariable = systemYAML.Login
+	system.PasswordVariable = systemYAML.Password
+
+	login, err := getEnvVariableValue(systemYAML.Login)
+	if err != nil {
+		return nil, err
+	}
+	
+	password, err := getEnvVariableValue(systemYAML.Password)
+	if err != nil {
+		return nil, err
+	}
+
+
+	reader := bufio.NewReader(os.Stdin)
+
+	if login == ""{
+		fmt.Printf("Please enter login for system %s:\n", system.Name)
+		login, _ = reader.ReadString('\n')
+	}
+	if password == ""{
+		fmt.Printf("Please enter password for system %s:\n", system.Name)
+		//password, _ = reader.ReadString('\n')
+		bytePassword, err := term.ReadPassword(int(syscall.Stdin))
+		if err != nil {
+			return  nil , err
+		}
+
+		password = string(bytePassword)
+	}
+
+
+	system.Client = cpiclient.NewCPIBasicAuthClient(strings.TrimSpace(login), strings.TrimSpace(password), systemYAML.Host, false)
+	
+	//TODO: Implement check connection
+	/*
+	log.Println("Checking connection...")
+	
+	err = system.Client.CheckConnection()
+	if err != nil {
+		log.Fatalln(err)
+		//return nil, err
+	}
+	*/
+
+	return system, nil
+}
+
 //TODO: Change to viper
 func getEnvVariableValue(variableName string) (string, error) {
 	value := os.Getenv(variableName)
@@ -0,0 +1,633 @@
+package landscape
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCompareConfiguration(t *testing.T) {
+	tenant := []*cpiclient.Configuration{
+		{ParameterKey: "Endpoint", ParameterValue: "/qa/orders", DataType: "xsd:string"},
+		{ParameterKey: "Host", ParameterValue: "changed.example.com", DataType: "xsd:string"},
+		{ParameterKey: "Timeout", ParameterValue: "60", DataType: "xsd:integer"},
+	}
+	declared := []*Parameter{
+		{Key: "Endpoint", Value: "/qa/orders", Type: "xsd:string"},
+		{Key: "Removed", Value: "x", Type: "xsd:string"},
+	}
+	original := []*cpiclient.Configuration{
+		{ParameterKey: "Endpoint", ParameterValue: "/orders", DataType: "xsd:string"},
+		{ParameterKey: "Host", ParameterValue: "dev.example.com", DataType: "xsd:string"},
+		{ParameterKey: "Timeout", ParameterValue: "60", DataType: "xsd:integer"},
+	}
+
+	expected := map[string]bool{
+		"Endpoint": false,
+		"Host":     true,
+		"Timeout":  false,
+		"Removed":  true,
+	}
+
+	states := CompareConfiguration(tenant, declared, original)
+	if len(states) != len(expected) {
+		t.Fatalf("Expected %d parameters, got %d", len(expected), len(states))
+	}
+	for _, state := range states {
+		if state.Drifted != expected[state.Key] {
+			t.Errorf("Parameter %s: expected drift %t, got %t", state.Key, expected[state.Key], state.Drifted)
+		}
+	}
+}
+
+func TestLandscapeFileChanges(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "landscape.yaml")
+	content := `#Test landscape
+landscape:
+  name: Test
+  packages:
+    - id: Orders
+      artifacts:
+        - id: Replicate_Orders
+          configurations:
+            - environment: QA
+              parameters:
+                - key: Endpoint
+                  value: /qa/orders
+  originalEnvironment: Dev
+`
+	if err := os.WriteFile(fileName, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	landscape := &Landscape{
+		FileName:            fileName,
+		Packages:            map[string]*Package{},
+		OriginalEnvironment: &Environment{Id: "Dev"},
+	}
+
+	err := landscape.SetArtifactParameters("Orders", "Replicate_Orders", "Prod", []*Parameter{
+		{Key: "Endpoint", Value: "/orders", Type: "xsd:string"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = landscape.SetArtifactParameters("Orders", "Replicate_Orders", "QA", []*Parameter{
+		{Key: "Endpoint", Value: "/qa/orders/v2", Type: "xsd:string"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := landscape.RemoveArtifactReferences("Orders", "Replicate_Orders", "Prod")
+	if err != nil || !removed {
+		t.Fatal("Expected configuration for Prod to be removed: ", err)
+	}
+
+	result, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(string(result), "#Test landscape") {
+		t.Error("Expected comment to be preserved")
+	}
+	if !strings.Contains(string(result), "value: /qa/orders/v2") {
+		t.Error("Expected QA value to be updated, got:\n", string(result))
+	}
+	if strings.Contains(string(result), "environment: Prod") {
+		t.Error("Expected Prod configuration to be removed, got:\n", string(result))
+	}
+}
+
+func TestTemplateBase(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "landscape.yaml")
+	content := `landscape:
+  packages:
+    - id: Orders
+      artifacts:
+        - id: Replicate_Orders
+          template: Template_Orders
+`
+	if err := os.WriteFile(fileName, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	artifact := &Artifact{Id: "Replicate_Orders", PackageId: "Orders"}
+	landscape := &Landscape{
+		FileName: fileName,
+		Packages: map[string]*Package{"Orders": {Id: "Orders", Artifacts: map[string]*Artifact{"Replicate_Orders": artifact}}},
+	}
+
+	base := NewTemplateBase("1.0.1", map[string][]byte{
+		"src/main/resources/script/prepare.groovy": []byte("return message"),
+		"src/main/resources/xsd/Orders.xsd":        []byte("<schema/>"),
+	})
+	if err := landscape.SetTemplateBase("Orders", "Replicate_Orders", "QA", base); err != nil {
+		t.Fatal(err)
+	}
+	if artifact.TemplateBases["QA"] != base {
+		t.Error("Expected template base to be set in landscape model")
+	}
+
+	landscapeYAML, _, err := ReadLandscapeYAML(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bases := newTemplateBases(landscapeYAML.Landscape.Packages[0].Artifacts[0].TemplateBase)
+	if bases["QA"] == nil || bases["QA"].Version != "1.0.1" || len(bases["QA"].Files) != 2 {
+		t.Fatal("Unexpected template base in landscape file ", bases)
+	}
+
+	changed := bases["QA"].ChangedFiles(map[string][]byte{
+		"src/main/resources/script/prepare.groovy": []byte("return null"),
+		"src/main/resources/script/log.groovy":     []byte(""),
+	})
+	expected := []string{"src/main/resources/script/log.groovy", "src/main/resources/script/prepare.groovy", "src/main/resources/xsd/Orders.xsd"}
+	if strings.Join(changed, ",") != strings.Join(expected, ",") {
+		t.Error("Unexpected changed files ", changed)
+	}
+}
+
+func TestNewLandscape(t *testing.T) {
+	for _, variable := range []string{"DEV_LOGIN_ENV_VAR", "DEV_PASSWORD_ENV_VAR", "PROD_LOGIN_ENV_VAR", "PROD_PASSWORD_ENV_VAR"} {
+		t.Setenv(variable, "test")
+	}
+
+	landscape, err := NewLandscape("../../conf/landscape-example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if landscape.OriginalEnvironment == nil || landscape.OriginalEnvironment.Id != "Dev" {
+		t.Fatal("Expected original environment Dev")
+	}
+	if landscape.Environments["QA"].Suffix != "QA" || landscape.Environments["QA"].System.Id != "dev" {
+		t.Error("Unexpected QA environment ", landscape.Environments["QA"])
+	}
+
+	parameters, _ := landscape.GetArtifactConfiguration("QA", "SAPHybrisCloudforCustomerIntegrationwithSAPCRM", "com.sap.scenarios.crm2cod.simpleconnectivity")
+	if len(parameters) != 3 || parameters[0].Type != "xsd:string" {
+		t.Error("Unexpected QA configuration ", parameters)
+	}
+}
+
+func TestDetectSuffixes(t *testing.T) {
+	ids := []string{"Orders", "OrdersQA", "OrdersPREPROD", "Order", "Invoices", "InvoicesQA"}
+
+	suffixes := DetectSuffixes(ids)
+	if strings.Join(suffixes, ",") != "PREPROD,QA" {
+		t.Fatal("Expected suffixes PREPROD,QA, got ", suffixes)
+	}
+
+	base, suffix := SplitSuffix("InvoicesQA", suffixes)
+	if base != "Invoices" || suffix != "QA" {
+		t.Error("Unexpected split result ", base, suffix)
+	}
+}
+
+func TestNamingRules(t *testing.T) {
+	defaultEnv := &Environment{Id: "QA", Suffix: "QA", Naming: newNamingRules(nil)}
+
+	if defaultEnv.PackageId("Orders") != "OrdersQA" || defaultEnv.BasePackageId("OrdersQA") != "Orders" {
+		t.Error("Unexpected default package ID")
+	}
+	if defaultEnv.PackageName("Orders") != "QA Orders" || defaultEnv.ArtifactName("Replicate") != "Replicate QA" {
+		t.Error("Unexpected default names")
+	}
+	if defaultEnv.BasePackageName("QA Orders") != "Orders" || defaultEnv.BaseArtifactName("Replicate QA") != "Replicate" {
+		t.Error("Unexpected default base names")
+	}
+	if defaultEnv.BasePackageShortText(defaultEnv.PackageShortText("Orders")) != "Orders" {
+		t.Error("Unexpected base short text")
+	}
+
+	prefixEnv := &Environment{Id: "QA", Suffix: "QA", Naming: newNamingRules(&NamingYAML{
+		PackageId:   &NamingRule{Prefix: "{suffix}_"},
+		ArtifactId:  &NamingRule{Prefix: "{suffix}_"},
+		PackageName: &NamingRule{Suffix: " [{env}]"},
+	})}
+
+	if prefixEnv.PackageId("Orders") != "QA_Orders" || prefixEnv.BasePackageId("QA_Orders") != "Orders" {
+		t.Error("Unexpected package ID ", prefixEnv.PackageId("Orders"))
+	}
+	if prefixEnv.ArtifactId("Replicate") != "QA_Replicate" || prefixEnv.BaseArtifactId("QA_Replicate") != "Replicate" {
+		t.Error("Unexpected artifact ID ", prefixEnv.ArtifactId("Replicate"))
+	}
+	if !prefixEnv.IsArtifactId("QA_Replicate") || prefixEnv.IsArtifactId("Replicate") {
+		t.Error("Unexpected artifact ID match")
+	}
+	if prefixEnv.PackageName("Orders") != "Orders [QA]" {
+		t.Error("Unexpected package name ", prefixEnv.PackageName("Orders"))
+	}
+	if prefixEnv.ArtifactName("Replicate") != "Replicate QA" {
+		t.Error("Expected default artifact name, got ", prefixEnv.ArtifactName("Replicate"))
+	}
+}
+
+func TestGetPackageEnvironment(t *testing.T) {
+	system := &System{Id: "dev"}
+	devEnv := &Environment{Id: "Dev", System: system, Naming: newNamingRules(nil)}
+	qaEnv := &Environment{Id: "QA", Suffix: "QA", System: system, Naming: newNamingRules(nil)}
+	prodEnv := &Environment{Id: "Prod", System: &System{Id: "prod"}, Naming: newNamingRules(nil)}
+
+	landscape := &Landscape{Environments: map[string]*Environment{"Dev": devEnv, "QA": qaEnv, "Prod": prodEnv}}
+
+	if env := landscape.GetPackageEnvironment(system, "OrdersQA"); env != qaEnv {
+		t.Error("Expected QA environment, got ", env)
+	}
+	if env := landscape.GetPackageEnvironment(system, "Orders"); env != devEnv {
+		t.Error("Expected Dev environment, got ", env)
+	}
+	if env := landscape.GetPackageEnvironment(prodEnv.System, "OrdersQA"); env != prodEnv {
+		t.Error("Expected Prod environment, got ", env)
+	}
+	if env := landscape.GetArtifactEnvironment(system, "ReplicateQA"); env != qaEnv {
+		t.Error("Expected QA environment, got ", env)
+	}
+}
+
+func TestReadLandscapeYAML(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"landscape.yaml": `landscape:
+  name: Test
+  include:
+    - teams/*.yaml
+  environments:
+    - id: Dev
+      system: dev
+  originalEnvironment: Dev
+`,
+		"teams/orders.yaml": `landscape:
+  packages:
+    - id: Orders
+      artifacts:
+        - id: Replicate_Orders
+`,
+		"landscape.d/invoices.yaml": `landscape:
+  packages:
+    - id: Invoices
+      artifacts:
+        - id: Replicate_Invoices
+`,
+	}
+	for name, content := range files {
+		fileName := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fileName, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	merged, packageFiles, err := ReadLandscapeYAML(filepath.Join(dir, "landscape.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged.Landscape.Packages) != 2 || len(merged.Landscape.Environments) != 1 || merged.Landscape.Include != nil {
+		t.Error("Unexpected merged landscape ", merged.Landscape)
+	}
+	if packageFiles["Invoices"] != filepath.Join(dir, "landscape.d", "invoices.yaml") {
+		t.Error("Unexpected file of package Invoices ", packageFiles["Invoices"])
+	}
+
+	//Same artifact in another package is a conflict
+	conflict := `landscape:
+  packages:
+    - id: OrdersCopy
+      artifacts:
+        - id: Replicate_Orders
+`
+	if err := os.WriteFile(filepath.Join(dir, "landscape.d", "conflict.yaml"), []byte(conflict), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = ReadLandscapeYAML(filepath.Join(dir, "landscape.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "artifact Replicate_Orders is declared in") {
+		t.Error("Expected conflict of artifact Replicate_Orders, got ", err)
+	}
+}
+
+func TestResolveLandscapeYAML(t *testing.T) {
+	landscapeYAML := &LandscapeYAML{}
+	err := yaml.Unmarshal([]byte(`landscape:
+  packages:
+    - id: Orders
+      defaults:
+        - parameters:
+            - key: Host
+              value: https://${env.backendHost}
+            - key: Endpoint
+              value: /${env.id}/orders
+        - environment: Prod
+          parameters:
+            - key: Endpoint
+              value: /orders
+      artifacts:
+        - id: Replicate_Orders
+          configurations:
+            - environment: QA
+              parameters:
+                - key: Endpoint
+                  value: /${env.id}/orders/v2
+  environments:
+    - id: QA
+      suffix: QA
+      system: dev
+      vars:
+        backendHost: qa.backend.local
+    - id: Prod
+      system: prod
+      vars:
+        backendHost: backend.local
+`), landscapeYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolved, err := ResolveLandscapeYAML(landscapeYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]map[string]string{
+		"QA":   {"Host": "https://qa.backend.local", "Endpoint": "/QA/orders/v2"},
+		"Prod": {"Host": "https://backend.local", "Endpoint": "/orders"},
+	}
+	configurations := resolved.Landscape.Packages[0].Artifacts[0].Configurations
+	if len(configurations) != 2 {
+		t.Fatal("Expected configurations for QA and Prod, got ", configurations)
+	}
+	for _, configuration := range configurations {
+		for _, parameter := range configuration.Parameters {
+			if expected[configuration.Environment][parameter.Key] != parameter.Value {
+				t.Errorf("%s %s: expected %s, got %s", configuration.Environment, parameter.Key, expected[configuration.Environment][parameter.Key], parameter.Value)
+			}
+			if parameter.Inherited != (parameter.Key == "Host" || configuration.Environment == "Prod") {
+				t.Errorf("%s %s: unexpected inherited flag", configuration.Environment, parameter.Key)
+			}
+		}
+	}
+	if content, err := yaml.Marshal(resolved); err != nil || strings.Contains(string(content), "inherited") {
+		t.Error("Inherited flag should not be written to landscape file ", err)
+	}
+
+	delete(landscapeYAML.Landscape.Environments[1].Vars, "backendHost")
+	_, err = ResolveLandscapeYAML(landscapeYAML)
+	if err == nil || !strings.Contains(err.Error(), "${env.backendHost}") {
+		t.Error("Expected error for undefined variable, got ", err)
+	}
+}
+
+func TestResolveParameters(t *testing.T) {
+	t.Setenv("TEST_API_KEY", "key-from-env")
+	fileName := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(fileName, []byte("key-from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	parameters := []*Parameter{
+		{Key: "Endpoint", Value: "/orders"},
+		{Key: "ApiKey", Value: "secret://env/TEST_API_KEY"},
+		{Key: "ClientSecret", Value: "secret://file/" + fileName},
+	}
+	resolved, err := ResolveParameters(parameters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resolved[0].Value != "/orders" || resolved[0].Sensitive {
+		t.Error("Unexpected plain parameter ", resolved[0])
+	}
+	if resolved[1].Value != "key-from-env" || !resolved[1].Sensitive {
+		t.Error("Unexpected parameter from environment variable ", resolved[1])
+	}
+	if resolved[2].Value != "key-from-file" || !resolved[2].Sensitive {
+		t.Error("Unexpected parameter from file ", resolved[2])
+	}
+	if parameters[1].Value != "secret://env/TEST_API_KEY" {
+		t.Error("Secret reference should not be replaced in landscape model")
+	}
+
+	_, err = ResolveParameters([]*Parameter{{Key: "Missing", Value: "secret://env/TEST_UNDEFINED_SECRET"}})
+	if err == nil {
+		t.Error("Expected error for undefined secret")
+	}
+}
+
+func TestGetArtifactConfiguration(t *testing.T) {
+	landscape := &Landscape{Packages: map[string]*Package{
+		"Orders": {
+			Id: "Orders",
+			Artifacts: map[string]*Artifact{
+				"Replicate_Orders": {Id: "Replicate_Orders", Configurations: map[string]*Configuration{
+					"QA": {Environment: "QA", Parameters: []*Parameter{{Key: "Endpoint", Value: "/qa"}}},
+				}},
+			},
+			Defaults: map[string]*Configuration{
+				"QA": {Environment: "QA", Parameters: []*Parameter{{Key: "Host", Value: "qa", Inherited: true}}},
+			},
+		},
+	}}
+
+	if parameters, err := landscape.GetArtifactConfiguration("QA", "Orders", "Replicate_Orders"); err != nil || len(parameters) != 1 || parameters[0].Key != "Endpoint" {
+		t.Error("Unexpected declared configuration ", parameters, err)
+	}
+	if parameters, err := landscape.GetArtifactConfiguration("QA", "Orders", "Cancel_Orders"); err != nil || len(parameters) != 1 || parameters[0].Key != "Host" {
+		t.Error("Expected package defaults for undeclared artifact, got ", parameters, err)
+	}
+	for _, ids := range [][]string{{"Prod", "Orders", "Replicate_Orders"}, {"Prod", "Orders", "Cancel_Orders"}, {"QA", "Invoices", "Replicate_Invoices"}} {
+		if parameters, err := landscape.GetArtifactConfiguration(ids[0], ids[1], ids[2]); err != nil || parameters != nil {
+			t.Errorf("Expected empty configuration for %v, got %v %v", ids, parameters, err)
+		}
+	}
+}
+
+func TestGetArtifactsByTemplate(t *testing.T) {
+	landscape := &Landscape{
+		Packages: map[string]*Package{
+			"Orders": {Id: "Orders", Artifacts: map[string]*Artifact{
+				"Orders_impl2": {Id: "Orders_impl2", PackageId: "Orders", Template: "Template1"},
+				"Orders_impl1": {Id: "Orders_impl1", PackageId: "Orders", Template: "Template1"},
+				"Orders_other": {Id: "Orders_other", PackageId: "Orders"},
+			}},
+			"Invoices": {Id: "Invoices", Artifacts: map[string]*Artifact{
+				"Invoices_impl": {Id: "Invoices_impl", PackageId: "Invoices", Template: "Template1"},
+			}},
+		},
+	}
+
+	artifacts := landscape.GetArtifactsByTemplate("Template1", "")
+	if len(artifacts) != 3 || artifacts[0].Id != "Invoices_impl" || artifacts[2].Id != "Orders_impl2" {
+		t.Error("Expected sorted implementations from all packages, got ", artifacts)
+	}
+
+	artifacts = landscape.GetArtifactsByTemplate("Template1", "Orders")
+	if len(artifacts) != 2 || artifacts[0].Id != "Orders_impl1" {
+		t.Error("Expected implementations from package Orders, got ", artifacts)
+	}
+}
+
+func TestParameterMapping(t *testing.T) {
+	mapping := &ParameterMapping{
+		Rename:   map[string]string{"Url": "Endpoint"},
+		Defaults: map[string]string{"Timeout": "60"},
+		Drop:     []string{"LegacyFlag"},
+	}
+	implementation := []*cpiclient.Configuration{
+		{ParameterKey: "Url", ParameterValue: "/orders", DataType: "xsd:string"},
+		{ParameterKey: "LegacyFlag", ParameterValue: "true", DataType: "xsd:boolean"},
+	}
+	template := []*cpiclient.Configuration{
+		{ParameterKey: "Endpoint", ParameterValue: "/template", DataType: "xsd:string"},
+		{ParameterKey: "Timeout", ParameterValue: "", DataType: "xsd:integer"},
+		{ParameterKey: "Retries", ParameterValue: "3", DataType: "xsd:integer"},
+	}
+
+	configurations, err := mapping.Complete(mapping.Apply(implementation), template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configurations) != 2 ||
+		configurations[0].ParameterKey != "Endpoint" || configurations[0].ParameterValue != "/orders" ||
+		configurations[1].ParameterKey != "Timeout" || configurations[1].DataType != "xsd:integer" {
+		t.Error("Unexpected mapped configuration ", configurations)
+	}
+	if implementation[0].ParameterKey != "Url" {
+		t.Error("Configuration of implementation should not be changed")
+	}
+
+	//Required parameter without value
+	delete(mapping.Defaults, "Timeout")
+	_, err = mapping.Complete(mapping.Apply(implementation), template)
+	if err == nil || !strings.Contains(err.Error(), "Timeout") {
+		t.Error("Expected error for required parameter Timeout, got ", err)
+	}
+
+	//Key, which is not mapped, does not exist in template
+	_, err = mapping.Complete([]*cpiclient.Configuration{{ParameterKey: "Unknown"}}, template)
+	if err == nil || !strings.Contains(err.Error(), "Unknown") {
+		t.Error("Expected error for unknown parameter, got ", err)
+	}
+
+	//Without mapping unknown keys are skipped
+	var noMapping *ParameterMapping
+	configurations, err = noMapping.Complete(noMapping.Apply(implementation), []*cpiclient.Configuration{
+		{ParameterKey: "Url", ParameterValue: "/template", DataType: "xsd:string"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configurations) != 1 || configurations[0].ParameterKey != "Url" {
+		t.Error("Expected unknown parameter LegacyFlag to be skipped, got ", configurations)
+	}
+}
+
+func TestParameterMappingMerge(t *testing.T) {
+	mapping := &ParameterMapping{
+		Rename:   map[string]string{"Url": "Endpoint"},
+		Defaults: map[string]string{"Timeout": "60"},
+		Drop:     []string{"LegacyFlag"},
+	}
+	implementation := []*cpiclient.Configuration{
+		{ParameterKey: "Url", ParameterValue: "/orders", DataType: "xsd:string"},
+	}
+	template := []*cpiclient.Configuration{
+		{ParameterKey: "Endpoint", ParameterValue: "/template", DataType: "xsd:string"},
+		{ParameterKey: "Timeout", ParameterValue: "", DataType: "xsd:integer"},
+	}
+	//Environment declares old keys, which are renamed and dropped in template
+	declared := []*Parameter{
+		{Key: "Url", Value: "/orders/qa"},
+		{Key: "LegacyFlag", Value: "true"},
+	}
+
+	configurations := mapping.Merge(mapping.Apply(implementation), declared)
+	configurations, err := mapping.Complete(configurations, template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(configurations) != 2 || configurations[0].ParameterKey != "Endpoint" || configurations[0].ParameterValue != "/orders/qa" {
+		t.Error("Unexpected merged configuration ", configurations)
+	}
+}
+
+func TestNewLintConfig(t *testing.T) {
+	config, err := newLintConfig(&LintYAML{
+		FailOn: "warning",
+		Rules: []LintRuleYAML{
+			{Id: "unused-parameter", Severity: "off"},
+			{Id: "hardcoded-endpoint", Exclude: []string{"Legacy_*"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.FailOn != "warning" || config.Rules["unused-parameter"].Severity != "off" || config.Rules["hardcoded-endpoint"].Exclude[0] != "Legacy_*" {
+		t.Error("Unexpected lint config ", config)
+	}
+
+	invalid := []*LintYAML{
+		{Rules: []LintRuleYAML{{Id: "unknown-rule"}}},
+		{Rules: []LintRuleYAML{{Id: "unused-parameter", Severity: "fatal"}}},
+		{FailOn: "off"},
+		{Rules: []LintRuleYAML{{Id: "unused-parameter"}, {Id: "unused-parameter"}}},
+	}
+	for _, lintYAML := range invalid {
+		if _, err := newLintConfig(lintYAML); err == nil {
+			t.Error("Expected error for lint config ", lintYAML)
+		}
+	}
+}
+
+func TestAuditConfiguration(t *testing.T) {
+	declared := []*Parameter{
+		{Key: "Endpoint", Value: "/QA/orders", Type: "xsd:string"},
+		{Key: "LegacyUrl", Value: "/QA/legacy", Type: "xsd:string"},
+		{Key: "Enabled", Value: "yes", Type: "xsd:string"},
+		{Key: "Timeout", Value: "60", Type: "xsd:string"},
+		{Key: "Retries", Value: "3", Type: "xsd:boolean"},
+		{Key: "Password", Value: "secret://env/QA_PASSWORD", Type: "xsd:string"},
+		{Key: "Proxy", Value: "none", Type: "xsd:string", Inherited: true},
+	}
+	tenant := []*cpiclient.Configuration{
+		{ParameterKey: "Endpoint", ParameterValue: "/QA/orders", DataType: "xsd:string"},
+		{ParameterKey: "Enabled", ParameterValue: "true", DataType: "xsd:boolean"},
+		{ParameterKey: "Timeout", ParameterValue: "60", DataType: "xsd:integer"},
+		{ParameterKey: "Retries", ParameterValue: "3", DataType: "xsd:integer"},
+		{ParameterKey: "Password", ParameterValue: "", DataType: "xsd:integer"},
+		{ParameterKey: "Host", ParameterValue: "qa.example.com", DataType: "xsd:string"},
+		{ParameterKey: "Client", ParameterValue: "100", DataType: "xsd:string"},
+	}
+	original := []*cpiclient.Configuration{
+		{ParameterKey: "Endpoint", ParameterValue: "/orders", DataType: "xsd:string"},
+		{ParameterKey: "Host", ParameterValue: "dev.example.com", DataType: "xsd:string"},
+		{ParameterKey: "Client", ParameterValue: "100", DataType: "xsd:string"},
+	}
+	findings := AuditConfiguration(declared, tenant, original)
+
+	expected := []AuditFinding{
+		{Key: "LegacyUrl", Issue: AuditUnknownKey},
+		{Key: "Enabled", Issue: AuditTypeMismatch},
+		{Key: "Retries", Issue: AuditTypeMismatch},
+		{Key: "Host", Issue: AuditUndeclaredDifference},
+	}
+	if len(findings) != len(expected) {
+		t.Fatal("Unexpected findings ", findings)
+	}
+	for index, finding := range findings {
+		if finding.Key != expected[index].Key || finding.Issue != expected[index].Issue {
+			t.Errorf("Expected %s %s, got %s %s", expected[index].Key, expected[index].Issue, finding.Key, finding.Issue)
+		}
+	}
+
+	//Original environment is not compared with itself
+	if findings := AuditConfiguration(nil, tenant, nil); len(findings) != 0 {
+		t.Error("Unexpected findings without declaration ", findings)
+	}
+}
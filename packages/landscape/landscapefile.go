@@ -0,0 +1,258 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package landscape
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+//Remove references to artifact from landscape file. If environment is original, whole artifact entry is removed,
+//otherwise only configuration for the environment. Returns false, if there was nothing to remove
+func (landscape *Landscape) RemoveArtifactReferences(pkg string, artifact string, environment string) (bool, error) {
+
+	fileName := landscape.getPackageFileName(pkg)
+	document, err := readLandscapeDocument(fileName)
+	if err != nil {
+		return false, err
+	}
+
+	artifacts := findMappingValue(findPackageNode(document, pkg), "artifacts")
+	artifactIndex := findSequenceItem(artifacts, "id", artifact)
+	if artifactIndex < 0 {
+		return false, nil
+	}
+
+	if landscape.OriginalEnvironment != nil && environment == landscape.OriginalEnvironment.Id {
+		removeSequenceItem(artifacts, artifactIndex)
+		if package_, ok := landscape.Packages[pkg]; ok {
+			delete(package_.Artifacts, artifact)
+		}
+	} else {
+		configurations := findMappingValue(artifacts.Content[artifactIndex], "configurations")
+		configurationIndex := findSequenceItem(configurations, "environment", environment)
+		if configurationIndex < 0 {
+			return false, nil
+		}
+		removeSequenceItem(configurations, configurationIndex)
+		if len(configurations.Content) == 0 {
+			removeMappingKey(artifacts.Content[artifactIndex], "configurations")
+		}
+		if package_, ok := landscape.Packages[pkg]; ok && package_.Artifacts[artifact] != nil {
+			delete(package_.Artifacts[artifact].Configurations, environment)
+		}
+	}
+
+	return true, writeLandscapeDocument(fileName, document)
+}
+
+//Set values of configuration parameters of artifact in environment. Missing package, artifact and configuration
+//entries are created
+func (landscape *Landscape) SetArtifactParameters(pkg string, artifact string, environment string, parameters []*Parameter) error {
+
+	fileName := landscape.getPackageFileName(pkg)
+	document, err := readLandscapeDocument(fileName)
+	if err != nil {
+		return err
+	}
+
+	landscapeNode := ensureMappingValue(document.Content[0], "landscape", yaml.MappingNode)
+	packageNode := ensureSequenceItem(ensureMappingValue(landscapeNode, "packages", yaml.SequenceNode), "id", pkg)
+	artifactNode := ensureSequenceItem(ensureMappingValue(packageNode, "artifacts", yaml.SequenceNode), "id", artifact)
+	configurationNode := ensureSequenceItem(ensureMappingValue(artifactNode, "configurations", yaml.SequenceNode), "environment", environment)
+	parametersNode := ensureMappingValue(configurationNode, "parameters", yaml.SequenceNode)
+
+	for _, parameter := range parameters {
+		parameterNode := ensureSequenceItem(parametersNode, "key", parameter.Key)
+		ensureMappingValue(parameterNode, "value", yaml.ScalarNode).SetString(parameter.Value)
+		if parameter.Type != "" && parameter.Type != "xsd:string" {
+			ensureMappingValue(parameterNode, "type", yaml.ScalarNode).SetString(parameter.Type)
+		}
+	}
+
+	err = writeLandscapeDocument(fileName, document)
+	if err != nil {
+		return err
+	}
+
+	landscape.setArtifactParameters(pkg, artifact, environment, parameters)
+
+	return nil
+}
+
+//Get file, where package is declared. New packages are added to main landscape file
+func (landscape *Landscape) getPackageFileName(pkg string) string {
+	if package_, ok := landscape.Packages[pkg]; ok && package_.FileName != "" {
+		return package_.FileName
+	}
+	return landscape.FileName
+}
+
+//Apply parameter changes to landscape model
+func (landscape *Landscape) setArtifactParameters(pkg string, artifact string, environment string, parameters []*Parameter) {
+	package_, ok := landscape.Packages[pkg]
+	if !ok {
+		package_ = &Package{Id: pkg, Artifacts: map[string]*Artifact{}}
+		landscape.Packages[pkg] = package_
+	}
+	artifact_, ok := package_.Artifacts[artifact]
+	if !ok {
+		artifact_ = &Artifact{Id: artifact, PackageId: pkg, Configurations: map[string]*Configuration{}}
+		package_.Artifacts[artifact] = artifact_
+	}
+	configuration, ok := artifact_.Configurations[environment]
+	if !ok {
+		configuration = &Configuration{Environment: environment}
+		artifact_.Configurations[environment] = configuration
+	}
+
+	for _, parameter := range parameters {
+		found := false
+		for _, existing := range configuration.Parameters {
+			if existing.Key == parameter.Key {
+				existing.Value = parameter.Value
+				existing.Type = parameter.Type
+				found = true
+			}
+		}
+		if !found {
+			configuration.Parameters = append(configuration.Parameters, parameter)
+		}
+	}
+}
+
+//Read landscape file as YAML node. Changes are performed on node level, so that comments and order of keys are preserved
+func readLandscapeDocument(fileName string) (*yaml.Node, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	document := &yaml.Node{}
+	err = yaml.Unmarshal(content, document)
+	if err != nil {
+		return nil, err
+	}
+	if document.Kind != yaml.DocumentNode || len(document.Content) == 0 {
+		return nil, fmt.Errorf("landscape file %s is empty", fileName)
+	}
+
+	return document, nil
+}
+
+func writeLandscapeDocument(fileName string, document *yaml.Node) error {
+	buffer := new(bytes.Buffer)
+
+	encoder := yaml.NewEncoder(buffer)
+	encoder.SetIndent(2)
+	err := encoder.Encode(document)
+	if err != nil {
+		return err
+	}
+	encoder.Close()
+
+	return os.WriteFile(fileName, buffer.Bytes(), 0644)
+}
+
+//Get package entry of landscape
+func findPackageNode(document *yaml.Node, pkg string) *yaml.Node {
+	packages := findMappingValue(findMappingValue(document.Content[0], "landscape"), "packages")
+	index := findSequenceItem(packages, "id", pkg)
+	if index < 0 {
+		return nil
+	}
+	return packages.Content[index]
+}
+
+//Get value of mapping node by key
+func findMappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+//Get value of mapping node by key, value node of provided kind is created, if key does not exist
+func ensureMappingValue(node *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+	value := findMappingValue(node, key)
+	if value != nil {
+		//Replace empty value, e.g. "artifacts:" without items
+		if value.Kind != kind && value.Tag == "!!null" {
+			value.Kind = kind
+			value.Tag = ""
+			value.Value = ""
+		}
+		//Empty flow sequence "[]" is extended in block style
+		if len(value.Content) == 0 {
+			value.Style = 0
+		}
+		return value
+	}
+
+	value = &yaml.Node{Kind: kind}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+
+	return value
+}
+
+//Get sequence item, which has provided value of key. Item is created, if it does not exist
+func ensureSequenceItem(node *yaml.Node, key string, value string) *yaml.Node {
+	index := findSequenceItem(node, key, value)
+	if index >= 0 {
+		return node.Content[index]
+	}
+
+	item := &yaml.Node{Kind: yaml.MappingNode}
+	ensureMappingValue(item, key, yaml.ScalarNode).SetString(value)
+	node.Content = append(node.Content, item)
+
+	return item
+}
+
+//Get index of sequence item, which has provided value of key
+func findSequenceItem(node *yaml.Node, key string, value string) int {
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return -1
+	}
+	for index, item := range node.Content {
+		keyNode := findMappingValue(item, key)
+		if keyNode != nil && keyNode.Value == value {
+			return index
+		}
+	}
+	return -1
+}
+
+func removeMappingKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func removeSequenceItem(node *yaml.Node, index int) {
+	node.Content = append(node.Content[:index], node.Content[index+1:]...)
+}
@@ -0,0 +1,65 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package landscape
+
+import (
+	"fmt"
+
+	"github.com/Trifolium-project/landscaper/packages/iflow"
+)
+
+//Lint block of landscape file. Rules, which are not listed, use default severity
+type LintYAML struct {
+	FailOn string         `yaml:"failOn,omitempty"`
+	Rules  []LintRuleYAML `yaml:"rules,omitempty"`
+}
+
+type LintRuleYAML struct {
+	Id       string   `yaml:"id"`
+	Severity string   `yaml:"severity,omitempty"`
+	Exclude  []string `yaml:"exclude,omitempty"`
+}
+
+func newLintConfig(lintYAML *LintYAML) (*iflow.LintConfig, error) {
+	config := &iflow.LintConfig{FailOn: iflow.SeverityError, Rules: make(map[string]*iflow.RuleConfig)}
+	if lintYAML == nil {
+		return config, nil
+	}
+
+	if lintYAML.FailOn != "" {
+		if err := iflow.ValidateSeverity(lintYAML.FailOn, false); err != nil {
+			return nil, fmt.Errorf("lint: %s", err)
+		}
+		config.FailOn = lintYAML.FailOn
+	}
+
+	for _, ruleYAML := range lintYAML.Rules {
+		if iflow.GetRule(ruleYAML.Id) == nil {
+			return nil, fmt.Errorf("lint: unknown rule %s", ruleYAML.Id)
+		}
+		if _, ok := config.Rules[ruleYAML.Id]; ok {
+			return nil, fmt.Errorf("lint: rule %s is declared more than once", ruleYAML.Id)
+		}
+		if ruleYAML.Severity != "" {
+			if err := iflow.ValidateSeverity(ruleYAML.Severity, true); err != nil {
+				return nil, fmt.Errorf("lint: rule %s: %s", ruleYAML.Id, err)
+			}
+		}
+		config.Rules[ruleYAML.Id] = &iflow.RuleConfig{Severity: ruleYAML.Severity, Exclude: ruleYAML.Exclude}
+	}
+
+	return config, nil
+}
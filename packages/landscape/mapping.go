@@ -0,0 +1,169 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package landscape
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/Trifolium-project/landscaper/packages/cpiclient"
+)
+
+//Changes of configuration keys, which are applied, when implementation is upgraded to new version of template
+type ParameterMapping struct {
+	//Old key - new key
+	Rename map[string]string
+	//Values of new keys
+	Defaults map[string]string
+	//Keys, which are removed from template
+	Drop []string
+}
+
+type ParameterMappingYAML struct {
+	Rename   map[string]string `yaml:"rename,omitempty"`
+	Defaults map[string]string `yaml:"defaults,omitempty"`
+	Drop     []string          `yaml:"drop,omitempty"`
+}
+
+func newParameterMapping(mappingYAML *ParameterMappingYAML) *ParameterMapping {
+	if mappingYAML == nil {
+		return nil
+	}
+	return &ParameterMapping{
+		Rename:   mappingYAML.Rename,
+		Defaults: mappingYAML.Defaults,
+		Drop:     mappingYAML.Drop,
+	}
+}
+
+//Rename and drop keys of implementation configuration
+func (mapping *ParameterMapping) Apply(configurations []*cpiclient.Configuration) []*cpiclient.Configuration {
+	var result []*cpiclient.Configuration
+	for _, configuration := range configurations {
+		mapped := *configuration
+		if mapping != nil {
+			if mapping.isDropped(mapped.ParameterKey) {
+				continue
+			}
+			if newKey, ok := mapping.Rename[mapped.ParameterKey]; ok {
+				mapped.ParameterKey = newKey
+			}
+		}
+		result = append(result, &mapped)
+	}
+	return result
+}
+
+//Override configuration with parameters, declared in landscape. Declared keys are renamed and dropped
+//the same way as keys of implementation. Inherited package defaults are applied only to existing keys
+func (mapping *ParameterMapping) Merge(configurations []*cpiclient.Configuration, declared []*Parameter) []*cpiclient.Configuration {
+	for _, parameter := range declared {
+		key := parameter.Key
+		if mapping != nil {
+			if mapping.isDropped(key) {
+				continue
+			}
+			if newKey, ok := mapping.Rename[key]; ok {
+				key = newKey
+			}
+		}
+
+		found := false
+		for _, configuration := range configurations {
+			if configuration.ParameterKey == key {
+				configuration.ParameterValue = parameter.Value
+				configuration.Sensitive = parameter.Sensitive
+				found = true
+			}
+		}
+		if !found && !parameter.Inherited {
+			configurations = append(configurations, &cpiclient.Configuration{
+				ParameterKey:   key,
+				ParameterValue: parameter.Value,
+				DataType:       parameter.Type,
+				Sensitive:      parameter.Sensitive,
+			})
+		}
+	}
+	return configurations
+}
+
+//Add default values of new keys and check configuration against template. If mapping is declared, every key should exist
+//in template, otherwise unknown keys are skipped. Parameters, which have no value in template, are required
+func (mapping *ParameterMapping) Complete(configurations []*cpiclient.Configuration, template []*cpiclient.Configuration) ([]*cpiclient.Configuration, error) {
+	templateConfigurations := make(map[string]*cpiclient.Configuration)
+	for _, configuration := range template {
+		templateConfigurations[configuration.ParameterKey] = configuration
+	}
+
+	existing := make(map[string]bool)
+	for _, configuration := range configurations {
+		existing[configuration.ParameterKey] = true
+	}
+
+	if mapping != nil {
+		var keys []string
+		for key := range mapping.Defaults {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if existing[key] {
+				continue
+			}
+			configurations = append(configurations, &cpiclient.Configuration{
+				ParameterKey:   key,
+				ParameterValue: mapping.Defaults[key],
+			})
+			existing[key] = true
+		}
+	}
+
+	var completed []*cpiclient.Configuration
+	for _, configuration := range configurations {
+		templateConfiguration, ok := templateConfigurations[configuration.ParameterKey]
+		if !ok {
+			if mapping != nil {
+				return nil, fmt.Errorf("parameter %s does not exist in template, rename or drop it in parameterMapping", configuration.ParameterKey)
+			}
+			log.Printf("Parameter %s is not found in template and is skipped, declare parameterMapping to rename or drop it", configuration.ParameterKey)
+			delete(existing, configuration.ParameterKey)
+			continue
+		}
+		//Type of parameter is defined by template
+		configuration.DataType = templateConfiguration.DataType
+		completed = append(completed, configuration)
+	}
+
+	for _, templateConfiguration := range template {
+		if !existing[templateConfiguration.ParameterKey] && templateConfiguration.ParameterValue == "" {
+			return nil, fmt.Errorf("required parameter %s of template has no value, add it to defaults of parameterMapping", templateConfiguration.ParameterKey)
+		}
+	}
+
+	return completed, nil
+}
+
+func (mapping *ParameterMapping) isDropped(key string) bool {
+	for _, dropped := range mapping.Drop {
+		if dropped == key {
+			return true
+		}
+	}
+	return false
+}
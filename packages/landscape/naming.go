@@ -0,0 +1,200 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package landscape
+
+import (
+	"sort"
+	"strings"
+)
+
+//Naming convention for copies of packages and artifacts in environment. Prefix and suffix are templates,
+//placeholders {env} and {suffix} are replaced with environment ID and suffix
+type NamingRule struct {
+	Prefix string `yaml:"prefix,omitempty"`
+	Suffix string `yaml:"suffix,omitempty"`
+}
+
+//Naming rules block of environment in landscape file. Rules, which are not set, use default convention
+type NamingYAML struct {
+	PackageId    *NamingRule `yaml:"packageId,omitempty"`
+	ArtifactId   *NamingRule `yaml:"artifactId,omitempty"`
+	PackageName  *NamingRule `yaml:"packageName,omitempty"`
+	ArtifactName *NamingRule `yaml:"artifactName,omitempty"`
+	ShortText    *NamingRule `yaml:"shortText,omitempty"`
+}
+
+type NamingRules struct {
+	PackageId    NamingRule
+	ArtifactId   NamingRule
+	PackageName  NamingRule
+	ArtifactName NamingRule
+	ShortText    NamingRule
+}
+
+//Default convention: "Id" + suffix for IDs, suffix + " " + name for packages, name + " " + suffix for artifacts
+func newNamingRules(namingYAML *NamingYAML) NamingRules {
+	rules := NamingRules{
+		PackageId:    NamingRule{Suffix: "{suffix}"},
+		ArtifactId:   NamingRule{Suffix: "{suffix}"},
+		PackageName:  NamingRule{Prefix: "{suffix} "},
+		ArtifactName: NamingRule{Suffix: " {suffix}"},
+		ShortText:    NamingRule{Suffix: "(environment - '{env}')"},
+	}
+	if namingYAML == nil {
+		return rules
+	}
+
+	if namingYAML.PackageId != nil {
+		rules.PackageId = *namingYAML.PackageId
+	}
+	if namingYAML.ArtifactId != nil {
+		rules.ArtifactId = *namingYAML.ArtifactId
+	}
+	if namingYAML.PackageName != nil {
+		rules.PackageName = *namingYAML.PackageName
+	}
+	if namingYAML.ArtifactName != nil {
+		rules.ArtifactName = *namingYAML.ArtifactName
+	}
+	if namingYAML.ShortText != nil {
+		rules.ShortText = *namingYAML.ShortText
+	}
+
+	return rules
+}
+
+func (env *Environment) expandNamingTemplate(template string) string {
+	return strings.NewReplacer("{env}", env.Id, "{suffix}", env.Suffix).Replace(template)
+}
+
+//Apply rule to value of original environment
+func (env *Environment) applyNamingRule(rule NamingRule, value string) string {
+	return env.expandNamingTemplate(rule.Prefix) + value + env.expandNamingTemplate(rule.Suffix)
+}
+
+//Restore value of original environment
+func (env *Environment) reverseNamingRule(rule NamingRule, value string) string {
+	value = strings.TrimSuffix(value, env.expandNamingTemplate(rule.Suffix))
+	return strings.TrimPrefix(value, env.expandNamingTemplate(rule.Prefix))
+}
+
+//Check, whether value follows the rule
+func (env *Environment) matchesNamingRule(rule NamingRule, value string) bool {
+	prefix := env.expandNamingTemplate(rule.Prefix)
+	suffix := env.expandNamingTemplate(rule.Suffix)
+
+	return len(value) > len(prefix)+len(suffix) && strings.HasPrefix(value, prefix) && strings.HasSuffix(value, suffix)
+}
+
+//Get ID of the package copy in environment
+func (env *Environment) PackageId(id string) string {
+	return env.applyNamingRule(env.Naming.PackageId, id)
+}
+
+//Get ID of the package in original environment
+func (env *Environment) BasePackageId(id string) string {
+	return env.reverseNamingRule(env.Naming.PackageId, id)
+}
+
+//Check, whether package ID follows naming convention of environment
+func (env *Environment) IsPackageId(id string) bool {
+	return env.matchesNamingRule(env.Naming.PackageId, id)
+}
+
+//Get name of the package copy in environment
+func (env *Environment) PackageName(name string) string {
+	return strings.TrimSpace(env.applyNamingRule(env.Naming.PackageName, name))
+}
+
+//Get name of the package in original environment
+func (env *Environment) BasePackageName(name string) string {
+	return strings.TrimSpace(env.reverseNamingRule(env.Naming.PackageName, name))
+}
+
+//Get short text of the package copy in environment
+func (env *Environment) PackageShortText(shortText string) string {
+	return env.applyNamingRule(env.Naming.ShortText, shortText)
+}
+
+//Get short text of the package in original environment
+func (env *Environment) BasePackageShortText(shortText string) string {
+	return env.reverseNamingRule(env.Naming.ShortText, shortText)
+}
+
+//Get ID of the artifact copy in environment
+func (env *Environment) ArtifactId(id string) string {
+	return env.applyNamingRule(env.Naming.ArtifactId, id)
+}
+
+//Get ID of the artifact in original environment
+func (env *Environment) BaseArtifactId(id string) string {
+	return env.reverseNamingRule(env.Naming.ArtifactId, id)
+}
+
+//Check, whether artifact ID follows naming convention of environment
+func (env *Environment) IsArtifactId(id string) bool {
+	return env.matchesNamingRule(env.Naming.ArtifactId, id)
+}
+
+//Get name of the artifact copy in environment
+func (env *Environment) ArtifactName(name string) string {
+	return strings.TrimSpace(env.applyNamingRule(env.Naming.ArtifactName, name))
+}
+
+//Get name of the artifact in original environment
+func (env *Environment) BaseArtifactName(name string) string {
+	return strings.TrimSpace(env.reverseNamingRule(env.Naming.ArtifactName, name))
+}
+
+//Find environment on system, to which package belongs. Several environments can share one system,
+//environment with the longest prefix and suffix of package ID wins, so copies are not attributed to original environment
+func (landscape *Landscape) GetPackageEnvironment(system *System, id string) *Environment {
+	return landscape.findEnvironment(system, func(env *Environment) (bool, int) {
+		return env.IsPackageId(id), len(env.PackageId(""))
+	})
+}
+
+//Find environment on system, to which artifact belongs. Rules are the same as for packages
+func (landscape *Landscape) GetArtifactEnvironment(system *System, id string) *Environment {
+	return landscape.findEnvironment(system, func(env *Environment) (bool, int) {
+		return env.IsArtifactId(id), len(env.ArtifactId(""))
+	})
+}
+
+//Environment on system, which matches ID with the longest affixes
+func (landscape *Landscape) findEnvironment(system *System, match func(env *Environment) (bool, int)) *Environment {
+	var found *Environment
+	foundLength := -1
+
+	var environmentIds []string
+	for environmentId := range landscape.Environments {
+		environmentIds = append(environmentIds, environmentId)
+	}
+	sort.Strings(environmentIds)
+
+	for _, environmentId := range environmentIds {
+		env := landscape.Environments[environmentId]
+		if env.System != system {
+			continue
+		}
+		matched, length := match(env)
+		if matched && length > foundLength {
+			found = env
+			foundLength = length
+		}
+	}
+	return found
+}
@@ -0,0 +1,217 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package landscape
+
+import (
+	"fmt"
+	"regexp"
+)
+
+//Reference to environment variable in parameter value, e.g. ${env.backendHost}
+var variablePattern = regexp.MustCompile(`\$\{env\.([A-Za-z0-9_.-]+)\}`)
+
+//Resolve landscape definition: package defaults are merged into configuration of every artifact, and variables
+//in parameter values are replaced with values of environment. Defaults without environment apply to all environments
+func ResolveLandscapeYAML(landscapeYAML *LandscapeYAML) (*LandscapeYAML, error) {
+	resolved := *landscapeYAML
+	resolved.Landscape.Packages = nil
+
+	environments := make(map[string]*EnvironmentYAML)
+	for index := range landscapeYAML.Landscape.Environments {
+		environmentYAML := &landscapeYAML.Landscape.Environments[index]
+		environments[environmentYAML.Id] = environmentYAML
+	}
+
+	for _, packageYAML := range landscapeYAML.Landscape.Packages {
+		defaults, err := resolvePackageDefaults(packageYAML, landscapeYAML.Landscape.Environments, environments)
+		if err != nil {
+			return nil, err
+		}
+
+		resolvedPackage := PackageYAML{Id: packageYAML.Id}
+		for _, environmentYAML := range landscapeYAML.Landscape.Environments {
+			if len(defaults[environmentYAML.Id]) > 0 {
+				resolvedPackage.Defaults = append(resolvedPackage.Defaults, ConfigurationYAML{
+					Environment: environmentYAML.Id,
+					Parameters:  defaults[environmentYAML.Id],
+				})
+			}
+		}
+
+		for _, artifactYAML := range packageYAML.Artifacts {
+			resolvedArtifact, err := resolveArtifact(artifactYAML, defaults, landscapeYAML.Landscape.Environments, environments)
+			if err != nil {
+				return nil, fmt.Errorf("package %s: %s", packageYAML.Id, err)
+			}
+			resolvedPackage.Artifacts = append(resolvedPackage.Artifacts, resolvedArtifact)
+		}
+
+		resolved.Landscape.Packages = append(resolved.Landscape.Packages, resolvedPackage)
+	}
+
+	return &resolved, nil
+}
+
+//Get resolved default parameters of package for each environment
+func resolvePackageDefaults(packageYAML PackageYAML, environmentList []EnvironmentYAML, environments map[string]*EnvironmentYAML) (map[string][]ParameterYAML, error) {
+	for _, defaultYAML := range packageYAML.Defaults {
+		if _, ok := environments[defaultYAML.Environment]; defaultYAML.Environment != "" && !ok {
+			return nil, fmt.Errorf("package %s: defaults refer to unknown environment %s", packageYAML.Id, defaultYAML.Environment)
+		}
+	}
+
+	defaults := make(map[string][]ParameterYAML)
+	for index := range environmentList {
+		environmentYAML := &environmentList[index]
+
+		var parameters []ParameterYAML
+		//Defaults for all environments first, so that environment specific ones override them
+		for _, environmentId := range []string{"", environmentYAML.Id} {
+			for _, defaultYAML := range packageYAML.Defaults {
+				if defaultYAML.Environment == environmentId {
+					parameters = mergeParameterYAML(parameters, defaultYAML.Parameters)
+				}
+			}
+		}
+
+		parameters, err := interpolateParameters(parameters, environmentYAML)
+		if err != nil {
+			return nil, fmt.Errorf("package %s: %s", packageYAML.Id, err)
+		}
+		for index := range parameters {
+			parameters[index].Inherited = true
+		}
+
+		defaults[environmentYAML.Id] = parameters
+	}
+
+	return defaults, nil
+}
+
+//Merge package defaults into artifact configurations and resolve variables
+func resolveArtifact(artifactYAML ArtifactYAML, defaults map[string][]ParameterYAML, environmentList []EnvironmentYAML, environments map[string]*EnvironmentYAML) (ArtifactYAML, error) {
+	resolved := ArtifactYAML{
+		Id:               artifactYAML.Id,
+		Template:         artifactYAML.Template,
+		ParameterMapping: artifactYAML.ParameterMapping,
+		TemplateBase:     artifactYAML.TemplateBase,
+	}
+
+	declared := make(map[string]bool)
+	for index := range environmentList {
+		environmentYAML := &environmentList[index]
+
+		parameters := defaults[environmentYAML.Id]
+		for _, configurationYAML := range artifactYAML.Configurations {
+			if configurationYAML.Environment == environmentYAML.Id {
+				declared[configurationYAML.Environment] = true
+				own, err := interpolateParameters(configurationYAML.Parameters, environmentYAML)
+				if err != nil {
+					return resolved, fmt.Errorf("artifact %s: %s", artifactYAML.Id, err)
+				}
+				parameters = mergeParameterYAML(parameters, own)
+			}
+		}
+
+		if len(parameters) > 0 || declared[environmentYAML.Id] {
+			resolved.Configurations = append(resolved.Configurations, ConfigurationYAML{
+				Environment: environmentYAML.Id,
+				Parameters:  parameters,
+			})
+		}
+	}
+
+	//Configurations of environments, which are not declared, are kept as is
+	for _, configurationYAML := range artifactYAML.Configurations {
+		if _, ok := environments[configurationYAML.Environment]; ok {
+			continue
+		}
+		parameters, err := interpolateParameters(configurationYAML.Parameters, nil)
+		if err != nil {
+			return resolved, fmt.Errorf("artifact %s: %s", artifactYAML.Id, err)
+		}
+		resolved.Configurations = append(resolved.Configurations, ConfigurationYAML{
+			Environment: configurationYAML.Environment,
+			Parameters:  parameters,
+		})
+	}
+
+	return resolved, nil
+}
+
+//Add parameters to list, values of existing keys are replaced
+func mergeParameterYAML(parameters []ParameterYAML, additional []ParameterYAML) []ParameterYAML {
+	result := append([]ParameterYAML{}, parameters...)
+
+	for _, parameter := range additional {
+		found := false
+		for index := range result {
+			if result[index].Key == parameter.Key {
+				result[index] = parameter
+				found = true
+			}
+		}
+		if !found {
+			result = append(result, parameter)
+		}
+	}
+
+	return result
+}
+
+func interpolateParameters(parameters []ParameterYAML, environmentYAML *EnvironmentYAML) ([]ParameterYAML, error) {
+	var result []ParameterYAML
+	for _, parameter := range parameters {
+		value, err := interpolate(parameter.Value, environmentYAML)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %s", parameter.Key, err)
+		}
+		parameter.Value = value
+		result = append(result, parameter)
+	}
+	return result, nil
+}
+
+//Replace ${env.id}, ${env.name}, ${env.suffix} and ${env.<variable>} with values of environment
+func interpolate(value string, environmentYAML *EnvironmentYAML) (string, error) {
+	var err error
+
+	result := variablePattern.ReplaceAllStringFunc(value, func(reference string) string {
+		name := variablePattern.FindStringSubmatch(reference)[1]
+		if environmentYAML == nil {
+			err = fmt.Errorf("variable %s cannot be resolved, environment is not declared", reference)
+			return reference
+		}
+
+		switch name {
+		case "id":
+			return environmentYAML.Id
+		case "name":
+			return environmentYAML.Name
+		case "suffix":
+			return environmentYAML.Suffix
+		}
+
+		variable, ok := environmentYAML.Vars[name]
+		if !ok {
+			err = fmt.Errorf("variable %s is not defined in environment %s", reference, environmentYAML.Id)
+			return reference
+		}
+		return variable
+	})
+
+	return result, err
+}
@@ -0,0 +1,96 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package landscape
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const secretScheme = "secret://"
+
+//Value, which is printed instead of secret
+const MaskedValue = "****"
+
+//Check, whether value references secret, which is not stored in landscape file
+func IsSecretReference(value string) bool {
+	return strings.HasPrefix(value, secretScheme)
+}
+
+//Get value of secret by reference:
+//secret://env/VARIABLE - value of environment variable(or .env file)
+//secret://file/path - content of file, path is relative to working directory
+func ResolveSecret(reference string) (string, error) {
+	split := strings.SplitN(strings.TrimPrefix(reference, secretScheme), "/", 2)
+	if len(split) != 2 || split[1] == "" {
+		return "", fmt.Errorf("invalid secret reference %s, expected secret://env/VARIABLE or secret://file/path", reference)
+	}
+
+	switch split[0] {
+	case "env":
+		value, ok := os.LookupEnv(split[1])
+		if !ok {
+			return "", fmt.Errorf("environment variable %s of secret reference is not set", split[1])
+		}
+		return value, nil
+	case "file":
+		content, err := os.ReadFile(split[1])
+		if err != nil {
+			return "", fmt.Errorf("unable to read secret file: %s", err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	default:
+		return "", fmt.Errorf("unknown secret provider %s in %s", split[0], reference)
+	}
+}
+
+//Get copy of parameters, where secret references are replaced with values. Such parameters are marked as sensitive
+func ResolveParameters(parameters []*Parameter) ([]*Parameter, error) {
+	var resolved []*Parameter
+	for _, parameter := range parameters {
+		resolvedParameter := *parameter
+		if IsSecretReference(parameter.Value) {
+			value, err := ResolveSecret(parameter.Value)
+			if err != nil {
+				return nil, fmt.Errorf("parameter %s: %s", parameter.Key, err)
+			}
+			resolvedParameter.Value = value
+			resolvedParameter.Sensitive = true
+		}
+		resolved = append(resolved, &resolvedParameter)
+	}
+	return resolved, nil
+}
+
+//Keys of parameters, which reference secrets
+func SecretKeys(parameters []*Parameter) map[string]bool {
+	keys := make(map[string]bool)
+	for _, parameter := range parameters {
+		if parameter.Sensitive || IsSecretReference(parameter.Value) {
+			keys[parameter.Key] = true
+		}
+	}
+	return keys
+}
+
+//Hide value of sensitive parameter
+func MaskValue(value string, sensitive bool) string {
+	if sensitive {
+		return MaskedValue
+	}
+	return value
+}
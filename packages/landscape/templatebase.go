@@ -0,0 +1,120 @@
+/*
+Copyright © 2022 Aleksandr Ivanov <shamrockspb@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package landscape
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+//Version and file hashes of template, from which implementation in environment was built. Recorded on upgrade,
+//so local modifications can be found without downloading old version of template
+type TemplateBase struct {
+	Version string
+	//Hash of every file, key is path in archive
+	Files map[string]string
+}
+
+type TemplateBaseYAML struct {
+	Environment string            `yaml:"environment"`
+	Version     string            `yaml:"version"`
+	Files       map[string]string `yaml:"files,omitempty"`
+}
+
+func newTemplateBases(basesYAML []TemplateBaseYAML) map[string]*TemplateBase {
+	bases := make(map[string]*TemplateBase)
+	for _, baseYAML := range basesYAML {
+		bases[baseYAML.Environment] = &TemplateBase{Version: baseYAML.Version, Files: baseYAML.Files}
+	}
+	return bases
+}
+
+//Template base from files of template version
+func NewTemplateBase(version string, files map[string][]byte) *TemplateBase {
+	base := &TemplateBase{Version: version, Files: make(map[string]string)}
+	for name, content := range files {
+		base.Files[name] = hashContent(content)
+	}
+	return base
+}
+
+//Files, which are changed, added or removed in comparison with template base
+func (base *TemplateBase) ChangedFiles(files map[string][]byte) []string {
+	var changed []string
+	for name, content := range files {
+		if hash, ok := base.Files[name]; !ok || hash != hashContent(content) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range base.Files {
+		if _, ok := files[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+//Record template base of artifact in environment
+func (landscape *Landscape) SetTemplateBase(pkg string, artifact string, environment string, base *TemplateBase) error {
+
+	fileName := landscape.getPackageFileName(pkg)
+	document, err := readLandscapeDocument(fileName)
+	if err != nil {
+		return err
+	}
+
+	landscapeNode := ensureMappingValue(document.Content[0], "landscape", yaml.MappingNode)
+	packageNode := ensureSequenceItem(ensureMappingValue(landscapeNode, "packages", yaml.SequenceNode), "id", pkg)
+	artifactNode := ensureSequenceItem(ensureMappingValue(packageNode, "artifacts", yaml.SequenceNode), "id", artifact)
+	baseNode := ensureSequenceItem(ensureMappingValue(artifactNode, "templateBase", yaml.SequenceNode), "environment", environment)
+	ensureMappingValue(baseNode, "version", yaml.ScalarNode).SetString(base.Version)
+
+	//Files are written in sorted order, so that changes of landscape file are readable
+	removeMappingKey(baseNode, "files")
+	filesNode := ensureMappingValue(baseNode, "files", yaml.MappingNode)
+	var names []string
+	for name := range base.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		ensureMappingValue(filesNode, name, yaml.ScalarNode).SetString(base.Files[name])
+	}
+
+	err = writeLandscapeDocument(fileName, document)
+	if err != nil {
+		return err
+	}
+
+	if package_, ok := landscape.Packages[pkg]; ok && package_.Artifacts[artifact] != nil {
+		artifact_ := package_.Artifacts[artifact]
+		if artifact_.TemplateBases == nil {
+			artifact_.TemplateBases = make(map[string]*TemplateBase)
+		}
+		artifact_.TemplateBases[environment] = base
+	}
+
+	return nil
+}
+
+func hashContent(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
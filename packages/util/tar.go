@@ -0,0 +1,115 @@
+package util
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//Pack directory content into gzip compressed tar archive
+func TarGzDirectory(dir string, fileName string) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gzipWriter := gzip.NewWriter(file)
+	archive := tar.NewWriter(gzipWriter)
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir || info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+
+		content, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer content.Close()
+		_, err = io.Copy(archive, content)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := archive.Close(); err != nil {
+		return err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+//Unpack gzip compressed tar archive into directory. Entries, which point outside of directory, are rejected
+func UntarGzToDirectory(fileName string, dir string) error {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	archive := tar.NewReader(gzipReader)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		path := filepath.Join(root, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(path, root+string(os.PathSeparator)) {
+			return fmt.Errorf("tar entry %s is outside of target directory", header.Name)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		target, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(target, archive)
+		target.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
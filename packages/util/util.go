@@ -1,5 +1,10 @@
 package util
 
+import (
+	"strconv"
+	"strings"
+)
+
 func Contains(s []string, e string) bool {
     for _, a := range s {
         if a == e {
@@ -19,4 +24,36 @@ func Contains[T comparable](s []T, e T) bool {
     }
     return false
 }
-*/
\ No newline at end of file
+*/
+
+//Compare dot separated versions, e.g. 1.0.10 and 1.0.9. Returns -1, 0 or 1. Numeric parts are compared as numbers
+func CompareVersions(a string, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		aPart, bPart := "0", "0"
+		if i < len(aParts) {
+			aPart = aParts[i]
+		}
+		if i < len(bParts) {
+			bPart = bParts[i]
+		}
+
+		aNumber, aErr := strconv.Atoi(aPart)
+		bNumber, bErr := strconv.Atoi(bPart)
+		switch {
+		case aErr == nil && bErr == nil && aNumber != bNumber:
+			if aNumber < bNumber {
+				return -1
+			}
+			return 1
+		case (aErr != nil || bErr != nil) && aPart != bPart:
+			if aPart < bPart {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
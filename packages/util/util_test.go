@@ -1,6 +1,12 @@
 package util
 
-import "testing"
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 
 
@@ -12,4 +18,151 @@ func TestContains(t *testing.T) {
 	if !(Contains(list, value) && true) {
 		t.Error("Expected true, got ", false)
 	}
-}
\ No newline at end of file
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"1.0.10", "1.0.9", 1},
+		{"1.0.2", "1.0.2", 0},
+		{"1.0", "1.0.1", -1},
+		{"1.0.0", "1.0", 0},
+	}
+	for _, c := range cases {
+		if result := CompareVersions(c.a, c.b); result != c.expected {
+			t.Errorf("CompareVersions(%s, %s): expected %d, got %d", c.a, c.b, c.expected, result)
+		}
+	}
+}
+
+func TestZipDirectory(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\n",
+		"src/main/resources/scenarioflows/integrationflow/Test.iflw": "<definitions/>",
+		".project": "skipped",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := ZipDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsZip(content) {
+		t.Fatal("Expected zip archive")
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(archive.File) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(archive.File))
+	}
+	if archive.File[0].Name != "META-INF/MANIFEST.MF" {
+		t.Error("Expected manifest as first entry, got ", archive.File[0].Name)
+	}
+
+	entries, err := ReadZipEntries(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(entries["src/main/resources/scenarioflows/integrationflow/Test.iflw"]) != "<definitions/>" {
+		t.Error("Unexpected entries ", entries)
+	}
+}
+
+func TestUnzipToDirectory(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	archive := zip.NewWriter(buffer)
+	files := map[string]string{
+		"META-INF/MANIFEST.MF": "Manifest-Version: 1.0\n",
+		".project":             "<projectDescription/>",
+	}
+	for name, content := range files {
+		writer, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		writer.Write([]byte(content))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := UnzipToDirectory(buffer.Bytes(), dir); err != nil {
+		t.Fatal(err)
+	}
+
+	//Hidden files are kept, when directory is packed back
+	content, err := ZipDirectoryAll(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ReadZipEntries(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || string(entries[".project"]) != "<projectDescription/>" {
+		t.Error("Unexpected entries ", entries)
+	}
+
+	buffer = new(bytes.Buffer)
+	archive = zip.NewWriter(buffer)
+	if _, err := archive.Create("../outside.txt"); err != nil {
+		t.Fatal(err)
+	}
+	archive.Close()
+	if err := UnzipToDirectory(buffer.Bytes(), t.TempDir()); err == nil {
+		t.Error("Expected error for entry outside of directory")
+	}
+}
+
+func TestTarGzDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"snapshot.yaml":                    "environment: Prod\n",
+		"Orders/iflows/Replicate/.project": "<projectDescription/>",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fileName := filepath.Join(t.TempDir(), "snapshot.tar.gz")
+	if err := TarGzDirectory(dir, fileName); err != nil {
+		t.Fatal(err)
+	}
+
+	target := t.TempDir()
+	if err := UntarGzToDirectory(fileName, target); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(target, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Errorf("Unexpected content of %s: %s", name, data)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package util
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const manifestPath = "META-INF/MANIFEST.MF"
+
+//Pack directory content into zip archive. Hidden files and folders(.git, .project etc.) are skipped,
+//manifest is always written as first entry
+func ZipDirectory(dir string) ([]byte, error) {
+	return zipDirectory(dir, false)
+}
+
+//Pack directory content into zip archive including hidden files, e.g. .project of exported artifact.
+//Only .git folder is skipped
+func ZipDirectoryAll(dir string) ([]byte, error) {
+	return zipDirectory(dir, true)
+}
+
+func zipDirectory(dir string, includeHidden bool) ([]byte, error) {
+	var files []string
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		hidden := strings.HasPrefix(info.Name(), ".") && (!includeHidden || info.Name() == ".git")
+		if path != dir && hidden {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]string)
+	var names []string
+	for _, file := range files {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return nil, err
+		}
+		name := filepath.ToSlash(rel)
+		entries[name] = file
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if names[i] == manifestPath || names[j] == manifestPath {
+			return names[i] == manifestPath
+		}
+		return names[i] < names[j]
+	})
+
+	buffer := new(bytes.Buffer)
+	archive := zip.NewWriter(buffer)
+
+	for _, name := range names {
+		writer, err := archive.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		file, err := os.Open(entries[name])
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.Copy(writer, file)
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = archive.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+//Check that content is zip archive
+func IsZip(content []byte) bool {
+	return len(content) > 4 && bytes.Equal(content[:4], []byte("PK\x03\x04"))
+}
+
+//Read content of all files in zip archive, key is path of file in archive
+func ReadZipEntries(content []byte) (map[string][]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string][]byte)
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		entries[file.Name] = data
+	}
+
+	return entries, nil
+}
+
+//Unpack zip archive into directory. Entries, which point outside of directory, are rejected
+func UnzipToDirectory(content []byte, dir string) error {
+	entries, err := ReadZipEntries(content)
+	if err != nil {
+		return err
+	}
+
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	for name, data := range entries {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if path != root && !strings.HasPrefix(path, root+string(os.PathSeparator)) {
+			return fmt.Errorf("zip entry %s is outside of target directory", name)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}